@@ -0,0 +1,172 @@
+// Package clipboard copies text to the system clipboard, to the terminal's
+// clipboard with OSC 52, or to a file.
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+var errNoTerminal = errors.New("no terminal to send OSC 52 to")
+
+// Backend is somewhere copied text can be written
+type Backend interface {
+	Write(text string) error
+	// Name describes where the text went, such as "clipboard" or a file path
+	Name() string
+}
+
+// backendNames lists the backends that can be chosen by name
+var backendNames = []string{"auto", "system", "osc52", "file"}
+
+// BackendNames returns the names accepted by New
+func BackendNames() []string {
+	return slices.Clone(backendNames)
+}
+
+// New returns the backend with the given name. "auto" (or "") tries the
+// system clipboard, then OSC 52 if tty is a terminal, then the file.
+// Over SSH OSC 52 is tried first, so that text reaches the local machine.
+// OSC 52 sequences are written to tty; path is the file for the file
+// backend, or "" for the default (see DefaultPath).
+func New(name string, tty io.Writer, path string) (Backend, error) {
+	if name != "" && !slices.Contains(backendNames, name) {
+		return nil, fmt.Errorf("unknown clipboard backend %q (available: %s)", name, strings.Join(backendNames, ", "))
+	}
+	if name == "system" {
+		return System{}, nil
+	}
+	term := NewOSC52(tty)
+	if name == "osc52" {
+		if tty == nil {
+			return nil, errNoTerminal
+		}
+		return term, nil
+	}
+
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	if name == "file" {
+		return File{Path: path}, nil
+	}
+	backends := []Backend{System{}, term}
+	if os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" {
+		backends = []Backend{term, System{}}
+	}
+	return &Fallback{Backends: append(backends, File{Path: path})}, nil
+}
+
+// DefaultPath returns the file the file backend writes to by default,
+// clipboard.txt in the user's cache directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ttree", "clipboard.txt"), nil
+}
+
+// System is the system clipboard. It needs pbcopy on macOS and xclip,
+// xsel or wl-copy on Linux.
+type System struct{}
+
+// Write implements Backend
+func (System) Write(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+// Name implements Backend
+func (System) Name() string {
+	return "clipboard"
+}
+
+// OSC52 asks the terminal to set its clipboard with an OSC 52 escape
+// sequence. This works over SSH in terminals that support it.
+type OSC52 struct {
+	Out  io.Writer  // The terminal
+	Mode osc52.Mode // Passthrough for tmux or screen
+}
+
+// NewOSC52 returns an OSC 52 backend writing to out, wrapping the sequence
+// for tmux or screen when running inside one
+func NewOSC52(out io.Writer) OSC52 {
+	mode := osc52.DefaultMode
+	if os.Getenv("TMUX") != "" {
+		mode = osc52.TmuxMode
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		mode = osc52.ScreenMode
+	}
+	return OSC52{Out: out, Mode: mode}
+}
+
+// Write implements Backend
+func (o OSC52) Write(text string) error {
+	if o.Out == nil {
+		return errNoTerminal
+	}
+	_, err := osc52.New(text).Mode(o.Mode).WriteTo(o.Out)
+	return err
+}
+
+// Name implements Backend
+func (o OSC52) Name() string {
+	return "terminal clipboard"
+}
+
+// File writes copied text to a file, replacing its contents
+type File struct {
+	Path string
+}
+
+// Write implements Backend
+func (f File) Write(text string) error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(f.Path, []byte(text), 0o600)
+}
+
+// Name implements Backend
+func (f File) Name() string {
+	return f.Path
+}
+
+// Fallback writes to the first of its backends that succeeds
+type Fallback struct {
+	Backends []Backend
+	used     Backend // Last backend written to
+}
+
+// Write implements Backend. If every backend fails, the errors are joined.
+func (f *Fallback) Write(text string) error {
+	var errs []error
+	for _, b := range f.Backends {
+		err := b.Write(text)
+		if err == nil {
+			f.used = b
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Name implements Backend, naming the backend last written to
+func (f *Fallback) Name() string {
+	if f.used == nil {
+		return "clipboard"
+	}
+	return f.used.Name()
+}
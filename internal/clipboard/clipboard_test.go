@@ -0,0 +1,171 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// fake is a backend that records what it was given, or fails
+type fake struct {
+	name string
+	err  error
+	text string
+}
+
+func (f *fake) Write(text string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.text = text
+	return nil
+}
+
+func (f *fake) Name() string {
+	return f.name
+}
+
+func TestOSC52(t *testing.T) {
+	tests := []struct {
+		mode     osc52.Mode
+		expected string
+	}{
+		{osc52.DefaultMode, "\x1b]52;c;YQpi\a"},
+		{osc52.TmuxMode, "\x1bPtmux;\x1b\x1b]52;c;YQpi\a\x1b\\"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := (OSC52{Out: &out, Mode: tt.mode}).Write("a\nb"); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		if out.String() != tt.expected {
+			t.Errorf("mode %v: expected %q, got %q", tt.mode, tt.expected, out.String())
+		}
+	}
+
+	if err := (OSC52{}).Write("a"); err == nil {
+		t.Error("expected an error without a terminal")
+	}
+}
+
+func TestNewOSC52DetectsTmux(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	if o := NewOSC52(nil); o.Mode != osc52.TmuxMode {
+		t.Errorf("expected tmux passthrough inside tmux, got %v", o.Mode)
+	}
+
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "screen-256color")
+	if o := NewOSC52(nil); o.Mode != osc52.ScreenMode {
+		t.Errorf("expected screen passthrough inside screen, got %v", o.Mode)
+	}
+
+	t.Setenv("TERM", "xterm-256color")
+	if o := NewOSC52(nil); o.Mode != osc52.DefaultMode {
+		t.Errorf("expected no passthrough, got %v", o.Mode)
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "clipboard.txt")
+	f := File{Path: path}
+
+	for _, text := range []string{"first\n", "second\n"} {
+		if err := f.Write(text); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != text {
+			t.Errorf("expected %q, got %q", text, data)
+		}
+	}
+	if f.Name() != path {
+		t.Errorf("expected the file to be named by its path, got %q", f.Name())
+	}
+}
+
+func TestFallback(t *testing.T) {
+	broken := &fake{name: "broken", err: errors.New("no xclip")}
+	working := &fake{name: "working"}
+	unused := &fake{name: "unused"}
+	f := &Fallback{Backends: []Backend{broken, working, unused}}
+
+	if err := f.Write("tree"); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if working.text != "tree" || unused.text != "" {
+		t.Errorf("expected only the first working backend to be used, got %q and %q", working.text, unused.text)
+	}
+	if f.Name() != "working" {
+		t.Errorf("expected the name of the backend used, got %q", f.Name())
+	}
+
+	f = &Fallback{Backends: []Backend{broken, &fake{err: errors.New("no terminal")}}}
+	err := f.Write("tree")
+	if err == nil || !strings.Contains(err.Error(), "no xclip") || !strings.Contains(err.Error(), "no terminal") {
+		t.Errorf("expected every error to be reported, got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clipboard.txt")
+	var tty bytes.Buffer
+
+	if b, err := New("system", nil, ""); err != nil || b.Name() != "clipboard" {
+		t.Errorf("system: got %v, %v", b, err)
+	}
+	if b, err := New("osc52", &tty, ""); err != nil || b.Name() != "terminal clipboard" {
+		t.Errorf("osc52: got %v, %v", b, err)
+	}
+	if _, err := New("osc52", nil, ""); err == nil {
+		t.Error("osc52: expected an error without a terminal")
+	}
+	if b, err := New("file", nil, path); err != nil || b.Name() != path {
+		t.Errorf("file: got %v, %v", b, err)
+	}
+	if _, err := New("pigeon", nil, ""); err == nil || !strings.Contains(err.Error(), "available: auto, system, osc52, file") {
+		t.Errorf("expected an error listing the backends, got %v", err)
+	}
+}
+
+func TestBackendNamesIsACopy(t *testing.T) {
+	names := BackendNames()
+	names[0] = "pigeon"
+	if _, err := New("auto", nil, ""); err != nil {
+		t.Errorf("expected changing the returned names to leave New alone, got %v", err)
+	}
+}
+
+func TestNewAuto(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clipboard.txt")
+	var tty bytes.Buffer
+
+	t.Setenv("SSH_TTY", "")
+	t.Setenv("SSH_CONNECTION", "")
+	b, err := New("auto", &tty, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backends := b.(*Fallback).Backends
+	if _, ok := backends[0].(System); !ok || len(backends) != 3 {
+		t.Errorf("expected the system clipboard first, got %v", backends)
+	}
+	if f, ok := backends[2].(File); !ok || f.Path != path {
+		t.Errorf("expected the file last, got %v", backends[2])
+	}
+
+	t.Setenv("SSH_TTY", "/dev/pts/1")
+	b, _ = New("", &tty, path)
+	if _, ok := b.(*Fallback).Backends[0].(OSC52); !ok {
+		t.Errorf("expected OSC 52 first over SSH, got %v", b.(*Fallback).Backends)
+	}
+}
@@ -0,0 +1,75 @@
+// Package config loads user preferences from a TOML file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/radish-miyazaki/ttree/internal/ui"
+)
+
+// Config holds the preferences read from the config file. Empty fields
+// leave the built-in defaults in place.
+type Config struct {
+	Style            string `toml:"style"`             // Tree style for the preview and copy
+	CommentAlign     string `toml:"comment_align"`     // "column" or "inline"
+	CommentDelimiter string `toml:"comment_delimiter"` // Also align text after this as a comment
+	Split            int    `toml:"split"`             // Editor share of the width, in percent
+	Clipboard        string `toml:"clipboard"`         // "auto", "system", "osc52" or "file"
+	ClipboardFile    string `toml:"clipboard_file"`    // Written by the file backend
+	Keys             Keys   `toml:"keys"`
+
+	File string `toml:"-"` // Where the config was read from, empty if there was no file
+}
+
+// Keys maps action names such as "up" or "paste_child" to keys, per mode.
+// Actions that aren't listed keep their default keys.
+type Keys struct {
+	Normal map[string][]string `toml:"normal"`
+	Edit   map[string][]string `toml:"edit"`
+}
+
+// Path returns the default config file location,
+// $XDG_CONFIG_HOME/ttree/config.toml or ~/.config/ttree/config.toml
+func Path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "ttree", "config.toml"), nil
+}
+
+// Load reads the config file at path. A missing file gives an empty
+// config; unknown settings are reported as errors.
+func Load(path string) (Config, error) {
+	var cfg Config
+	md, err := toml.DecodeFile(path, &cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, k := range undecoded {
+			keys[i] = k.String()
+		}
+		return Config{}, fmt.Errorf("%s: unknown setting %s", path, strings.Join(keys, ", "))
+	}
+	if cfg.Split != 0 {
+		if err := ui.CheckSplit(cfg.Split); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	cfg.File = path
+	return cfg, nil
+}
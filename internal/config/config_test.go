@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `style = "rounded"
+comment_align = "inline"
+comment_delimiter = "--"
+split = 40
+clipboard = "file"
+clipboard_file = "/tmp/tree.txt"
+
+[keys.normal]
+up = ["k", "ctrl+p"]
+paste_child = []
+
+[keys.edit]
+save = ["ctrl+w"]
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.File != path {
+		t.Errorf("File = %q, expected %q", cfg.File, path)
+	}
+	if cfg.Clipboard != "file" || cfg.ClipboardFile != "/tmp/tree.txt" {
+		t.Errorf("unexpected clipboard settings: %q, %q", cfg.Clipboard, cfg.ClipboardFile)
+	}
+	if cfg.Style != "rounded" || cfg.CommentAlign != "inline" || cfg.CommentDelimiter != "--" || cfg.Split != 40 {
+		t.Errorf("unexpected preferences: %+v", cfg)
+	}
+	if got := cfg.Keys.Normal["up"]; len(got) != 2 || got[1] != "ctrl+p" {
+		t.Errorf("normal up = %v, expected [k ctrl+p]", got)
+	}
+	if got, ok := cfg.Keys.Normal["paste_child"]; !ok || len(got) != 0 {
+		t.Errorf("normal paste_child = %v (listed %v), expected an empty list", got, ok)
+	}
+	if got := cfg.Keys.Edit["save"]; len(got) != 1 || got[0] != "ctrl+w" {
+		t.Errorf("edit save = %v, expected [ctrl+w]", got)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil {
+		t.Fatalf("expected a missing file to be ignored, got %v", err)
+	}
+	if cfg.File != "" || cfg.Style != "" || cfg.Keys.Normal != nil {
+		t.Errorf("expected an empty config, got %+v", cfg)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{`stlye = "ascii"`, "unknown setting stlye"},
+		{`[keys.insert]` + "\n" + `up = ["k"]`, "unknown setting keys.insert"},
+		{`split = 120`, "split must be a percentage between 20 and 80"},
+		{`split = 10`, "split must be a percentage between 20 and 80"},
+		{`split = -5`, "split must be a percentage"},
+		{`split = "half"`, "split"},
+		{`[keys.normal]` + "\n" + `up = "k"`, "up"},
+	}
+
+	for _, tt := range tests {
+		path := writeConfig(t, tt.content)
+		_, err := Load(path)
+		if err == nil {
+			t.Errorf("%q: expected an error", tt.content)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), path+": ") || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: error %q should name the file and contain %q", tt.content, err, tt.want)
+		}
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	if path, err := Path(); err != nil || path != "/tmp/xdg/ttree/config.toml" {
+		t.Errorf("Path() = %q, %v", path, err)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "/home/someone")
+	if path, err := Path(); err != nil || path != "/home/someone/.config/ttree/config.toml" {
+		t.Errorf("Path() = %q, %v", path, err)
+	}
+}
@@ -0,0 +1,93 @@
+// Package dirtree builds trees from directories on disk.
+package dirtree
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+var errNotDir = errors.New("not a directory")
+
+// Options controls which entries are imported
+type Options struct {
+	MaxDepth   int  // Maximum depth to descend (0 = unlimited)
+	Hidden     bool // Include entries starting with "."
+	GitIgnore  bool // Skip entries matched by .gitignore files
+	DirsSuffix bool // Append "/" to directory names
+}
+
+// DefaultOptions returns the default import options
+func DefaultOptions() Options {
+	return Options{
+		GitIgnore:  true,
+		DirsSuffix: true,
+	}
+}
+
+// Build walks dir and returns it as a tree. Like tree(1), the directory is
+// the only top-level node, named as given, and entries are sorted by name.
+func Build(dir string, opts Options) (*tree.Tree, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &os.PathError{Op: "import", Path: dir, Err: errNotDir}
+	}
+
+	root := tree.NewNode("root")
+	top := tree.NewNode(dir)
+	root.AddChild(top)
+	b := builder{dir: dir, opts: opts}
+	if err := b.walk(top, "", 1, nil); err != nil {
+		return nil, err
+	}
+	return &tree.Tree{Root: root}, nil
+}
+
+type builder struct {
+	dir  string
+	opts Options
+}
+
+// walk adds the entries of the directory rel (relative to b.dir) to parent
+func (b *builder) walk(parent *tree.Node, rel string, depth int, rules []ignoreRule) error {
+	full := filepath.Join(b.dir, filepath.FromSlash(rel))
+	entries, err := os.ReadDir(full)
+	if err != nil {
+		return err
+	}
+	if b.opts.GitIgnore {
+		rules = append(rules[:len(rules):len(rules)], readIgnoreFile(full, rel)...)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !b.opts.Hidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+		isDir := entry.IsDir()
+		childRel := path.Join(rel, name)
+		if b.opts.GitIgnore && (name == ".git" || ignored(rules, childRel, isDir)) {
+			continue
+		}
+
+		text := name
+		if isDir && b.opts.DirsSuffix {
+			text += "/"
+		}
+		node := tree.NewNode(text)
+		parent.AddChild(node)
+
+		if isDir && (b.opts.MaxDepth == 0 || depth < b.opts.MaxDepth) {
+			// Unreadable subdirectories are listed without contents
+			_ = b.walk(node, childRel, depth+1, rules)
+		}
+	}
+	return nil
+}
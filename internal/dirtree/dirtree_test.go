@@ -0,0 +1,171 @@
+package dirtree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/render"
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// contents returns the entries below the directory node of tr
+func contents(t *testing.T, tr *tree.Tree) *tree.Tree {
+	t.Helper()
+	if len(tr.Root.Children) != 1 {
+		t.Fatalf("expected the directory as the only top-level node, got %d nodes", len(tr.Root.Children))
+	}
+	return &tree.Tree{Root: tr.Root.Children[0]}
+}
+
+// writeFiles creates the given files (and their parent directories) under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"src/components/Header.tsx": "",
+		"src/utils/helpers.ts":      "",
+		"README.md":                 "",
+	})
+
+	tr, err := Build(dir, DefaultOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `├── README.md
+└── src/
+    ├── components/
+    │   └── Header.tsx
+    └── utils/
+        └── helpers.ts
+`
+	if output := render.NewRenderer().Render(contents(t, tr)); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestBuildNamesDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"main.go": ""})
+
+	tr, err := Build(dir, DefaultOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := contents(t, tr).Root.Text; text != dir {
+		t.Errorf("expected the directory to be named %q, got %q", dir, text)
+	}
+}
+
+func TestBuildMaxDepth(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a/b/c.txt": ""})
+
+	opts := DefaultOptions()
+	opts.MaxDepth = 2
+	tr, err := Build(dir, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "a/\n  b/\n"
+	if output := render.Outline(contents(t, tr)); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestBuildHidden(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{".env": "", "main.go": ""})
+
+	tr, _ := Build(dir, DefaultOptions())
+	if output := render.Outline(contents(t, tr)); output != "main.go\n" {
+		t.Errorf("expected hidden files to be skipped, got %q", output)
+	}
+
+	opts := DefaultOptions()
+	opts.Hidden = true
+	tr, _ = Build(dir, opts)
+	if output := render.Outline(contents(t, tr)); output != ".env\nmain.go\n" {
+		t.Errorf("expected hidden files to be included, got %q", output)
+	}
+}
+
+func TestBuildGitIgnore(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".gitignore":        "*.log\n/build/\n!keep.log\n",
+		"app.log":           "",
+		"keep.log":          "",
+		"build/out":         "",
+		"src/build/gen.go":  "",
+		"src/.gitignore":    "gen.go\n",
+		"src/main.go":       "",
+		"docs/notes/a.tmp":  "",
+		"docs/.gitignore":   "notes/**/*.tmp\n",
+		"docs/notes/b.md":   "",
+		".git/HEAD":         "",
+		"node_modules/x.js": "",
+	})
+
+	opts := DefaultOptions()
+	opts.Hidden = true
+	opts.DirsSuffix = false
+	tr, err := Build(dir, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `.gitignore
+docs
+  .gitignore
+  notes
+    b.md
+keep.log
+node_modules
+  x.js
+src
+  .gitignore
+  build
+  main.go
+`
+	if output := render.Outline(contents(t, tr)); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestBuildEmptyDir(t *testing.T) {
+	tr, err := Build(t.TempDir(), DefaultOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if top := contents(t, tr).Root; len(top.Children) != 0 {
+		t.Errorf("expected no entries in an empty directory, got %d", len(top.Children))
+	}
+}
+
+func TestBuildNotDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"file": ""})
+
+	if _, err := Build(filepath.Join(dir, "file"), DefaultOptions()); err == nil {
+		t.Error("expected error for a regular file")
+	}
+	if _, err := Build(filepath.Join(dir, "missing"), DefaultOptions()); err == nil {
+		t.Error("expected error for a missing directory")
+	}
+}
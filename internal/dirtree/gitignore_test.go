@@ -0,0 +1,61 @@
+package dirtree
+
+import "testing"
+
+func TestParseIgnoreRule(t *testing.T) {
+	tests := []struct {
+		line string
+		ok   bool
+		rule ignoreRule
+	}{
+		{"", false, ignoreRule{}},
+		{"# comment", false, ignoreRule{}},
+		{"*.log", true, ignoreRule{pattern: "*.log"}},
+		{"!keep.log", true, ignoreRule{pattern: "keep.log", negate: true}},
+		{"build/", true, ignoreRule{pattern: "build", dirOnly: true}},
+		{"/dist", true, ignoreRule{pattern: "dist", anchored: true}},
+		{"docs/*.md  ", true, ignoreRule{pattern: "docs/*.md", anchored: true}},
+		{`\#file`, true, ignoreRule{pattern: "#file"}},
+	}
+
+	for _, tt := range tests {
+		rule, ok := parseIgnoreRule(tt.line, "")
+		if ok != tt.ok || rule != tt.rule {
+			t.Errorf("parseIgnoreRule(%q) = %+v, %v; expected %+v, %v", tt.line, rule, ok, tt.rule, tt.ok)
+		}
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	rules := []ignoreRule{
+		{pattern: "*.log"},
+		{pattern: "keep.log", negate: true},
+		{pattern: "tmp", dirOnly: true},
+		{base: "web", pattern: "dist", anchored: true},
+		{pattern: "a/**/z", anchored: true},
+	}
+
+	tests := []struct {
+		rel      string
+		isDir    bool
+		expected bool
+	}{
+		{"app.log", false, true},
+		{"sub/app.log", false, true},
+		{"keep.log", false, false},
+		{"tmp", true, true},
+		{"tmp", false, false},
+		{"web/dist", true, true},
+		{"dist", true, false},
+		{"web/sub/dist", true, false},
+		{"a/z", false, true},
+		{"a/b/c/z", false, true},
+		{"b/a/z", false, false},
+	}
+
+	for _, tt := range tests {
+		if result := ignored(rules, tt.rel, tt.isDir); result != tt.expected {
+			t.Errorf("ignored(%q, %v) = %v, expected %v", tt.rel, tt.isDir, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package document
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/radish-miyazaki/ttree/internal/render"
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// reportLine matches the summary printed after a tree by tree(1)
+var reportLine = regexp.MustCompile(`^\d+ director(y|ies)(, \d+ files?)?$`)
+
+// parseASCII parses text rendered with the given style. A leading line
+// without a branch (such as the "." printed by tree(1)) becomes the parent
+// of the rest of the tree, and a trailing tree(1) report is set aside; both
+// are recorded in the format so that the text can be written back as it
+// was. It returns false if the text is not in that style.
+func parseASCII(text string, style render.Style) (*tree.Tree, Format, bool) {
+	format := Format{ASCII: true, Style: style}
+	lines := splitLines(text)
+	if n := len(lines); n > 1 && reportLine.MatchString(lines[n-1]) {
+		format.Footer = lines[n-1] + "\n"
+		lines = lines[:n-1]
+		for len(lines) > 0 && lines[len(lines)-1] == "" {
+			format.Footer = "\n" + format.Footer
+			lines = lines[:len(lines)-1]
+		}
+	}
+	if len(lines) == 0 {
+		return nil, format, false
+	}
+
+	root := tree.NewNode("root")
+	parents := []*tree.Node{root}
+	if _, _, _, ok := parseASCIILine(lines[0], style); !ok && len(lines) > 1 {
+		header := format.newNode(strings.TrimSpace(lines[0]))
+		root.AddChild(header)
+		parents = append(parents, header)
+		lines = lines[1:]
+		format.Header = true
+	}
+	base := len(parents) - 1
+
+	for _, line := range lines {
+		depth, text, nbsp, ok := parseASCIILine(line, style)
+		if !ok {
+			return nil, format, false
+		}
+		format.NBSP = format.NBSP || nbsp
+		level := base + depth
+		if level > len(parents)-1 {
+			level = len(parents) - 1
+		}
+
+		node := format.newNode(text)
+		parents[level].AddChild(node)
+		parents = append(parents[:level+1], node)
+	}
+	return &tree.Tree{Root: root}, format, true
+}
+
+// parseASCIILine splits a rendered line into its depth and node text. It
+// also reports whether the indentation used non-breaking spaces.
+func parseASCIILine(line string, style render.Style) (int, string, bool, bool) {
+	depth := 0
+	nbsp := false
+	for {
+		if rest, ok := cutIndent(line, style.Vertical); ok {
+			nbsp = nbsp || strings.Contains(line[:len(line)-len(rest)], nonBreakingSpace)
+			line = rest
+		} else if rest, ok := cutIndent(line, style.Space); ok {
+			nbsp = nbsp || strings.Contains(line[:len(line)-len(rest)], nonBreakingSpace)
+			line = rest
+		} else {
+			break
+		}
+		depth++
+	}
+
+	for _, branch := range []string{style.Branch, style.LastBranch} {
+		if rest, ok := cutPrefix(line, branch); ok {
+			// Empty nodes are rendered as a single space
+			if rest == " " {
+				rest = ""
+			}
+			return depth, rest, nbsp, true
+		}
+		// Editors often strip the trailing whitespace of empty nodes
+		if trimmed := strings.TrimRight(branch, " "); trimmed != "" && line == trimmed {
+			return depth, "", nbsp, true
+		}
+	}
+	return 0, "", false, false
+}
+
+// nonBreakingSpace is used by tree(1) in the indentation of its output
+const nonBreakingSpace = "\u00a0"
+
+// cutIndent is cutPrefix for a level of indentation, where a non-breaking
+// space in s matches a space in indent
+func cutIndent(s, indent string) (string, bool) {
+	if indent == "" {
+		return s, false
+	}
+	for _, r := range indent {
+		c, size := utf8.DecodeRuneInString(s)
+		if size == 0 || (c != r && !(r == ' ' && c == '\u00a0')) {
+			return s, false
+		}
+		s = s[size:]
+	}
+	return s, true
+}
+
+// withNBSP draws a prefix the way tree(1) does, with non-breaking spaces
+// after the vertical line
+func withNBSP(prefix string, style render.Style) string {
+	line := strings.TrimRight(style.Vertical, " ")
+	spaces := len(style.Vertical) - len(line)
+	if spaces < 2 {
+		return prefix
+	}
+	nbsp := line + strings.Repeat(nonBreakingSpace, spaces-1) + " "
+	return strings.ReplaceAll(prefix, style.Vertical, nbsp)
+}
+
+// cutPrefix is strings.CutPrefix that never matches an empty prefix
+func cutPrefix(s, prefix string) (string, bool) {
+	if prefix == "" {
+		return s, false
+	}
+	return strings.CutPrefix(s, prefix)
+}
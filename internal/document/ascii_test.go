@@ -0,0 +1,83 @@
+package document
+
+import (
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/render"
+)
+
+func TestParseASCIIRoundTrip(t *testing.T) {
+	inputs := []string{
+		"└── item\n",
+		"├── item1\n├── item2\n└── item3\n",
+		"├── folder1\n│   ├── file1\n│   └── file2\n└── folder2\n    └── file3\n",
+		"└── a\n    └── b\n        └── c\n            └── d\n",
+		"├── a\n│   └──  \n├──  \n└── c\n    ├── c1\n    └── c2\n",
+	}
+
+	r := render.NewRenderer()
+	for _, input := range inputs {
+		tr, _, ok := parseASCII(input, render.DefaultStyle())
+		if !ok {
+			t.Errorf("failed to parse %q", input)
+			continue
+		}
+		if output := r.Render(tr); output != input {
+			t.Errorf("round trip mismatch:\nexpected %q\ngot      %q", input, output)
+		}
+	}
+}
+
+func TestParseASCIIEmptyText(t *testing.T) {
+	tr, _, ok := parseASCII("└──  \n", render.DefaultStyle())
+
+	if !ok {
+		t.Fatal("expected parse to succeed")
+	}
+	if tr.Root.Children[0].Text != "" {
+		t.Errorf("expected empty text, got %q", tr.Root.Children[0].Text)
+	}
+}
+
+func TestParseASCIIStrippedTrailingSpace(t *testing.T) {
+	tr, _, ok := parseASCII("├──\n└── b\n", render.DefaultStyle())
+
+	if !ok {
+		t.Fatal("expected parse to succeed")
+	}
+	if tr.Root.Children[0].Text != "" {
+		t.Errorf("expected empty text, got %q", tr.Root.Children[0].Text)
+	}
+}
+
+func TestParseASCIIHeaderLine(t *testing.T) {
+	tr, _, ok := parseASCII(".\n├── a\n└── b\n", render.DefaultStyle())
+
+	if !ok {
+		t.Fatal("expected parse to succeed")
+	}
+	if len(tr.Root.Children) != 1 || tr.Root.Children[0].Text != "." {
+		t.Fatalf("expected header as single top-level node, got %q", render.Outline(tr))
+	}
+	if len(tr.Root.Children[0].Children) != 2 {
+		t.Errorf("expected 2 children under header, got %d", len(tr.Root.Children[0].Children))
+	}
+}
+
+func TestParseASCIIRejectsOutline(t *testing.T) {
+	if _, _, ok := parseASCII("a\n  b\n", render.DefaultStyle()); ok {
+		t.Error("expected outline text to be rejected")
+	}
+	if _, _, ok := parseASCII("", render.DefaultStyle()); ok {
+		t.Error("expected empty text to be rejected")
+	}
+}
+
+func TestParseASCIIDepth(t *testing.T) {
+	tr, _, _ := parseASCII("└── a\n    └── b\n        └── c\n", render.DefaultStyle())
+
+	c := tr.Root.Children[0].Children[0].Children[0]
+	if c.Text != "c" || c.Depth() != 3 {
+		t.Errorf("expected c at depth 3, got %q at depth %d", c.Text, c.Depth())
+	}
+}
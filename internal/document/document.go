@@ -0,0 +1,142 @@
+// Package document reads and writes trees from files and streams.
+package document
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/radish-miyazaki/ttree/internal/render"
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// Format identifies a text representation of a tree
+type Format struct {
+	ASCII bool         // Tree drawn by render.Renderer rather than an indented outline
+	Style render.Style // Style of an ASCII tree
+
+	// tree(1) output, written back as it was read
+	Header bool   // The first line is a top-level node drawn without a branch
+	Footer string // Report after the tree, such as "\n2 directories, 3 files\n"
+	NBSP   bool   // Indentation uses non-breaking spaces
+
+	// Spaces before each comment as read, by node ID, so that saving keeps
+	// the spacing of the file
+	CommentGaps map[string]int
+}
+
+var (
+	// FormatOutline is an indented plain-text outline
+	FormatOutline = Format{}
+	// FormatASCII is a tree drawn with render.DefaultStyle
+	FormatASCII = Format{ASCII: true, Style: render.DefaultStyle()}
+)
+
+// Load reads a tree from the file at path
+func Load(path string) (*tree.Tree, Format, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, FormatOutline, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read parses a tree from r
+func Read(r io.Reader) (*tree.Tree, Format, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, FormatOutline, err
+	}
+	t, format := Parse(string(data))
+	return t, format, nil
+}
+
+// Parse builds a tree from text, detecting its format.
+// The result always contains at least one node.
+func Parse(text string) (*tree.Tree, Format) {
+	t, format := parse(text)
+	if len(t.Root.Children) == 0 {
+		t.Root.AddChild(tree.NewNode(""))
+	}
+	return t, format
+}
+
+func parse(text string) (*tree.Tree, Format) {
+	// Lists are read before the markdown style so that they keep their
+	// bullets and indentation
+	if t, format, ok := parseMarkdown(text); ok {
+		return t, format
+	}
+	for _, name := range render.StyleNames() {
+		style, _ := render.LookupStyle(name)
+		if t, format, ok := parseASCII(text, style); ok {
+			return t, format
+		}
+	}
+	return parseOutline(text)
+}
+
+// Save writes the tree to the file at path in the given format
+func Save(path string, t *tree.Tree, format Format) error {
+	return os.WriteFile(path, []byte(Write(t, format)), 0o644)
+}
+
+// Write returns the text representation of the tree in the given format.
+// Collapsed nodes are always included.
+func Write(t *tree.Tree, format Format) string {
+	if !format.ASCII {
+		return render.OutlineWithGaps(t, format.CommentGaps)
+	}
+	r := render.NewRenderer()
+	r.Style = format.Style
+	r.IncludeCollapsed = true
+
+	var sb strings.Builder
+	if format.Header && len(t.Root.Children) == 1 {
+		// Draw the top-level node as a header above its children
+		header := t.Root.Children[0]
+		sb.WriteString(header.Text)
+		if header.Comment != "" {
+			gap, ok := format.CommentGaps[header.ID]
+			if !ok {
+				gap = len(commentSeparator) - len(render.CommentMarker)
+			}
+			sb.WriteString(strings.Repeat(" ", gap) + render.CommentMarker + header.Comment)
+		}
+		sb.WriteString("\n")
+		t = &tree.Tree{Root: header}
+	}
+	for _, line := range r.Lines(t) {
+		if format.NBSP {
+			line.Prefix = withNBSP(line.Prefix, format.Style)
+		}
+		if gap, ok := format.CommentGaps[line.NodeID]; ok && line.Marker != "" {
+			line.Gap = gap
+		}
+		sb.WriteString(line.String() + "\n")
+	}
+	sb.WriteString(format.Footer)
+	return sb.String()
+}
+
+// commentSeparator separates a node's text from its comment: at least two
+// spaces followed by render.CommentMarker
+const commentSeparator = "  " + render.CommentMarker
+
+// newNode returns a node for a line's text, splitting off any comment and
+// recording the spaces before it
+func (f *Format) newNode(text string) *tree.Node {
+	i := strings.Index(text, commentSeparator)
+	if i < 0 {
+		return tree.NewNode(text)
+	}
+	before := strings.TrimRight(text[:i], " ")
+	node := tree.NewNode(before)
+	node.Comment = text[i+len(commentSeparator):]
+	if f.CommentGaps == nil {
+		f.CommentGaps = make(map[string]int)
+	}
+	f.CommentGaps[node.ID] = i + len(commentSeparator) - len(render.CommentMarker) - len(before)
+	return node
+}
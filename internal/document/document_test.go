@@ -0,0 +1,216 @@
+package document
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/render"
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+func TestParseEmpty(t *testing.T) {
+	tr, _ := Parse("")
+
+	if len(tr.Root.Children) != 1 {
+		t.Fatalf("expected 1 placeholder node, got %d", len(tr.Root.Children))
+	}
+	if tr.Root.Children[0].Text != "" {
+		t.Errorf("expected empty placeholder, got %q", tr.Root.Children[0].Text)
+	}
+}
+
+func TestRead(t *testing.T) {
+	tr, format, err := Read(strings.NewReader("a\n  b\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if format.ASCII {
+		t.Errorf("expected outline format, got %v", format)
+	}
+
+	if len(tr.Root.Children) != 1 || len(tr.Root.Children[0].Children) != 1 {
+		t.Errorf("unexpected structure: %q", render.Outline(tr))
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	text := "src\n  components\n    Header.tsx\n  \nREADME.md\n"
+	path := filepath.Join(t.TempDir(), "tree.txt")
+
+	tr, _ := Parse(text)
+	if err := Save(path, tr, FormatOutline); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	tr, _, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if output := render.Outline(tr); output != text {
+		t.Errorf("expected %q, got %q", text, output)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, _, err := Load(filepath.Join(t.TempDir(), "missing.txt"))
+
+	if !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+}
+
+func TestParseDetectsASCII(t *testing.T) {
+	tr, format := Parse("├── a\n│   └── b\n└── c\n")
+
+	if !format.ASCII || format.Style != FormatASCII.Style {
+		t.Errorf("expected ASCII format, got %v", format)
+	}
+	if len(tr.Root.Children) != 2 || tr.Root.Children[0].Children[0].Text != "b" {
+		t.Errorf("unexpected structure: %q", render.Outline(tr))
+	}
+}
+
+func TestSaveLoadASCIIRoundTrip(t *testing.T) {
+	text := "├── src\n│   ├── components\n│   │   └──  \n│   └── utils\n└── README.md\n"
+	path := filepath.Join(t.TempDir(), "tree.txt")
+
+	tr, _ := Parse(text)
+	tr.Root.Children[0].Expanded = false
+	if err := Save(path, tr, FormatASCII); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	if string(data) != text {
+		t.Errorf("expected %q, got %q", text, string(data))
+	}
+}
+
+func TestParseDetectsStyle(t *testing.T) {
+	for _, name := range render.StyleNames() {
+		style, _ := render.LookupStyle(name)
+		r := render.NewRenderer()
+		r.Style = style
+		text := "├── a\n│   └──  \n└── b\n"
+		tr, _ := Parse(text)
+		rendered := r.Render(tr)
+
+		parsed, format := Parse(rendered)
+		if !format.ASCII || format.Style != style {
+			t.Errorf("%s: expected style to be detected, got %+v", name, format)
+			continue
+		}
+		if output := Write(parsed, format); output != rendered {
+			t.Errorf("%s: round trip mismatch: expected %q, got %q", name, rendered, output)
+		}
+	}
+}
+
+func TestNewNodeSplitsComment(t *testing.T) {
+	tests := []struct {
+		line, text, comment string
+		gap                 int
+	}{
+		{"main.go", "main.go", "", 0},
+		{"main.go  # entry point", "main.go", "entry point", 2},
+		{"main.go        # entry point", "main.go", "entry point", 8},
+		{"C# notes", "C# notes", "", 0},
+		{"a # b", "a # b", "", 0},
+		{"   # only a comment", "", "only a comment", 3},
+	}
+	for _, tt := range tests {
+		var f Format
+		n := f.newNode(tt.line)
+		if n.Text != tt.text || n.Comment != tt.comment || f.CommentGaps[n.ID] != tt.gap {
+			t.Errorf("newNode(%q) = %q, %q, gap %d; want %q, %q, gap %d",
+				tt.line, n.Text, n.Comment, f.CommentGaps[n.ID], tt.text, tt.comment, tt.gap)
+		}
+	}
+}
+
+func TestCommentsRoundTrip(t *testing.T) {
+	for _, text := range []string{
+		"src\n  main.go  # entry point\nREADME.md  # docs\n",
+		"├── src\n│   └── main.go  # entry point\n└── README.md    # docs\n",
+	} {
+		tr, format := Parse(text)
+		if comment := tr.Root.Children[0].Children[0].Comment; comment != "entry point" {
+			t.Errorf("expected comment 'entry point', got %q", comment)
+		}
+		if output := Write(tr, format); output != text {
+			t.Errorf("expected:\n%s\ngot:\n%s", text, output)
+		}
+	}
+}
+
+func TestUnevenCommentsRoundTrip(t *testing.T) {
+	for _, text := range []string{
+		"├── foo  # bar\n└── longername  # baz\n",
+		"├── a     # one\n│   └── b   # two\n└── c  # three\n",
+		"src    # sources\n  main.go  # entry point\n",
+		"- foo   # bar\n  - longername  # baz\n",
+		".    # here\n├── a\n└── b  # bee\n",
+	} {
+		tr, format := Parse(text)
+		if output := Write(tr, format); output != text {
+			t.Errorf("expected:\n%s\ngot:\n%s", text, output)
+		}
+	}
+}
+
+func TestWriteAlignsNewComments(t *testing.T) {
+	tr, format := Parse("├── foo  # bar\n└── longername\n")
+	tr.Root.Children[1].Comment = "baz"
+	expected := "├── foo  # bar\n└── longername  # baz\n"
+	if output := Write(tr, format); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestTreeOutputRoundTrip(t *testing.T) {
+	inputs := []string{
+		".\n├── a\n└── c\n",
+		"src  # sources\n├── a\n│   └── b\n└── c\n",
+		".\n├── a\n│   ├── b\n│   └── c\n└── d\n    └── e\n\n2 directories, 3 files\n",
+		"├── a\n└── b\n\n0 directories, 2 files\n",
+		"└── a\n1 directory\n",
+	}
+
+	for _, input := range inputs {
+		tr, format := Parse(input)
+		if !format.ASCII {
+			t.Errorf("expected %q to be read as an ASCII tree", input)
+			continue
+		}
+		if output := Write(tr, format); output != input {
+			t.Errorf("round trip mismatch:\nexpected %q\ngot      %q", input, output)
+		}
+	}
+}
+
+func TestTreeOutputStructure(t *testing.T) {
+	tr, format := Parse(".\n├── a\n│   └── b\n└── c\n\n1 directory, 2 files\n")
+
+	if !format.Header || !format.NBSP || format.Footer != "\n1 directory, 2 files\n" {
+		t.Errorf("unexpected format %+v", format)
+	}
+	expected := ".\n  a\n    b\n  c\n"
+	if output := render.Outline(tr); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestWriteHeaderWithSeveralTopLevelNodes(t *testing.T) {
+	tr, format := Parse(".\n├── a\n└── c\n")
+	tr.Root.AddChild(tree.NewNode("d"))
+
+	expected := "├── .\n│   ├── a\n│   └── c\n└── d\n"
+	if output := Write(tr, format); output != expected {
+		t.Errorf("expected the header to be drawn as a node, got %q", output)
+	}
+}
@@ -0,0 +1,87 @@
+package document
+
+import (
+	"cmp"
+	"strings"
+
+	"github.com/radish-miyazaki/ttree/internal/render"
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// markdownBullets are the markers of unordered Markdown list items
+var markdownBullets = []string{"- ", "* ", "+ "}
+
+// parseMarkdown parses a Markdown bullet list. An item is a child of the
+// nearest item above it with less indentation, so any consistent
+// indentation works; a tab counts as four spaces. The format draws the list
+// with the first item's bullet and the list's indentation, so that it is
+// saved as it was written. It returns false unless every non-blank line is
+// a list item.
+func parseMarkdown(text string) (*tree.Tree, Format, bool) {
+	type item struct {
+		indent int
+		node   *tree.Node
+	}
+
+	var format Format
+	root := tree.NewNode("root")
+	var stack []item
+	bullet := ""
+	unit := 0 // Smallest indentation, in columns
+	tabs := false
+	for _, line := range splitLines(text) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		tabs = tabs || strings.HasPrefix(line, "\t")
+		line = strings.ReplaceAll(line, "\t", "    ")
+		body := strings.TrimLeft(line, " ")
+		text, ok := cutBullet(body)
+		if !ok {
+			return nil, Format{}, false
+		}
+		if bullet == "" {
+			bullet = body[:1]
+		}
+		indent := len(line) - len(body)
+		if indent > 0 && (unit == 0 || indent < unit) {
+			unit = indent
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := root
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1].node
+		}
+		node := format.newNode(text)
+		parent.AddChild(node)
+		stack = append(stack, item{indent, node})
+	}
+	if len(stack) == 0 {
+		return nil, Format{}, false
+	}
+
+	indent := strings.Repeat(" ", cmp.Or(unit, len(render.OutlineIndent)))
+	if tabs {
+		indent = "\t"
+	}
+	format.ASCII = true
+	format.Style = render.Style{Branch: bullet + " ", LastBranch: bullet + " ", Vertical: indent, Space: indent}
+	return &tree.Tree{Root: root}, format, true
+}
+
+// cutBullet removes the list marker from the start of an item
+func cutBullet(s string) (string, bool) {
+	for _, bullet := range markdownBullets {
+		if rest, ok := strings.CutPrefix(s, bullet); ok {
+			return rest, true
+		}
+		// Editors often strip the trailing whitespace of empty items
+		if s == strings.TrimRight(bullet, " ") {
+			return "", true
+		}
+	}
+	return "", false
+}
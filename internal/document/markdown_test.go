@@ -0,0 +1,83 @@
+package document
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/render"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	tests := map[string]string{
+		"dashes":     "- a\n  - a1\n    - a2\n- b\n",
+		"stars":      "* a\n  * a1\n    * a2\n* b\n",
+		"four":       "+ a\n    + a1\n        + a2\n+ b\n",
+		"tabs":       "- a\n\t- a1\n\t\t- a2\n- b\n",
+		"mixed":      "* a\n  - a1\n     + a2\n* b\n",
+		"loose":      "- a\n\n  - a1\n    - a2\n\n- b\n",
+		"indented":   "  - a\n    - a1\n      - a2\n  - b\n",
+		"empty item": "- a\n  - a1\n    - a2\n- b\n-\n",
+	}
+
+	for name, text := range tests {
+		tr, _, ok := parseMarkdown(text)
+		if !ok {
+			t.Errorf("%s: expected a Markdown list", name)
+			continue
+		}
+		if got := render.Outline(tr); !strings.HasPrefix(got, "a\n  a1\n    a2\nb\n") {
+			t.Errorf("%s: unexpected tree %q", name, got)
+		}
+	}
+}
+
+func TestParseMarkdownKeepsCommentsAndEmptyItems(t *testing.T) {
+	tr, _, _ := parseMarkdown("* a  # note\n*\n")
+
+	if a := tr.Root.Children[0]; a.Text != "a" || a.Comment != "note" {
+		t.Errorf("expected comment to be split off, got %q, %q", a.Text, a.Comment)
+	}
+	if len(tr.Root.Children) != 2 || tr.Root.Children[1].Text != "" {
+		t.Error("expected a bare marker to become an empty item")
+	}
+}
+
+func TestParseMarkdownRejectsOtherText(t *testing.T) {
+	for _, text := range []string{"", "a\n* b\n", "* a\n  b\n", "*bold*\n", "1. a\n2. b\n"} {
+		if _, _, ok := parseMarkdown(text); ok {
+			t.Errorf("expected %q not to be a Markdown list", text)
+		}
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	inputs := []string{
+		"- a\n  - a1\n- b\n",
+		"* a\n    * a1\n        * a2\n* b\n",
+		"+ a\n   + a1\n+ b\n",
+		"- a\n\t- a1\n\t\t- a2\n- b\n",
+	}
+
+	for _, input := range inputs {
+		tr, format := Parse(input)
+		if !format.ASCII {
+			t.Errorf("expected %q to be read as a list", input)
+			continue
+		}
+		if output := Write(tr, format); output != input {
+			t.Errorf("round trip mismatch:\nexpected %q\ngot      %q", input, output)
+		}
+	}
+}
+
+func TestParseMarkdownStyle(t *testing.T) {
+	_, format := Parse("- a\n  - a1\n")
+	if name := render.StyleName(format.Style); name != "markdown" {
+		t.Errorf("expected a dash list with two spaces to use the markdown style, got %q", name)
+	}
+
+	_, format = Parse("* a\n    * a1\n")
+	if format.Style.Branch != "* " || format.Style.Vertical != "    " {
+		t.Errorf("expected the list's bullet and indentation, got %+v", format.Style)
+	}
+}
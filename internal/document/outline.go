@@ -0,0 +1,63 @@
+package document
+
+import (
+	"strings"
+
+	"github.com/radish-miyazaki/ttree/internal/render"
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// parseOutline parses an indented outline. Each tab counts as one level;
+// runs of spaces are measured against the smallest space indentation found.
+func parseOutline(text string) (*tree.Tree, Format) {
+	format := FormatOutline
+	lines := splitLines(text)
+	unit := indentUnit(lines)
+
+	root := tree.NewNode("root")
+	parents := []*tree.Node{root}
+	for _, line := range lines {
+		body := strings.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(body)]
+
+		level := strings.Count(indent, "\t") + strings.Count(indent, " ")/unit
+		if level > len(parents)-1 {
+			level = len(parents) - 1
+		}
+
+		node := format.newNode(body)
+		parents[level].AddChild(node)
+		parents = append(parents[:level+1], node)
+	}
+	return &tree.Tree{Root: root}, format
+}
+
+// splitLines splits text into lines, dropping trailing blank lines.
+// Lines may end with \n, \r\n or a lone \r (as in terminal pastes).
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	lines := strings.Split(text, "\n")
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// indentUnit returns the smallest number of leading spaces on a non-blank line
+func indentUnit(lines []string) int {
+	unit := 0
+	for _, line := range lines {
+		body := strings.TrimLeft(line, " ")
+		if strings.TrimSpace(body) == "" {
+			continue
+		}
+		if n := len(line) - len(body); n > 0 && (unit == 0 || n < unit) {
+			unit = n
+		}
+	}
+	if unit == 0 {
+		unit = len(render.OutlineIndent)
+	}
+	return unit
+}
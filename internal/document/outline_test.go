@@ -0,0 +1,77 @@
+package document
+
+import "testing"
+
+func TestParseOutline(t *testing.T) {
+	tr, _ := parseOutline("a\n  a1\n    a2\nb\n")
+
+	if len(tr.Root.Children) != 2 {
+		t.Fatalf("expected 2 root children, got %d", len(tr.Root.Children))
+	}
+	a := tr.Root.Children[0]
+	if a.Text != "a" || len(a.Children) != 1 {
+		t.Fatalf("unexpected first node: %q with %d children", a.Text, len(a.Children))
+	}
+	if a.Children[0].Children[0].Text != "a2" {
+		t.Errorf("expected a2 at depth 3, got %q", a.Children[0].Children[0].Text)
+	}
+	if tr.Root.Children[1].Text != "b" {
+		t.Errorf("expected b, got %q", tr.Root.Children[1].Text)
+	}
+}
+
+func TestParseOutlineDetectsIndentUnit(t *testing.T) {
+	tr, _ := parseOutline("a\n    b\n        c\n")
+
+	c := tr.Root.Children[0].Children[0].Children[0]
+	if c.Text != "c" {
+		t.Errorf("expected c at depth 3, got %q", c.Text)
+	}
+}
+
+func TestParseOutlineTabs(t *testing.T) {
+	tr, _ := parseOutline("a\n\tb\n\t\tc\n")
+
+	if c := tr.Root.Children[0].Children[0].Children[0]; c.Text != "c" {
+		t.Errorf("expected c at depth 3, got %q", c.Text)
+	}
+}
+
+func TestParseOutlineClampsIndentJumps(t *testing.T) {
+	tr, _ := parseOutline("a\n      b\n")
+
+	if len(tr.Root.Children[0].Children) != 1 {
+		t.Fatal("expected b to become a child of a")
+	}
+	if b := tr.Root.Children[0].Children[0]; b.Depth() != 2 {
+		t.Errorf("expected depth 2, got %d", b.Depth())
+	}
+}
+
+func TestParseOutlineBlankLines(t *testing.T) {
+	tr, _ := parseOutline("a\n  \nb\n\n\n")
+
+	if len(tr.Root.Children) != 2 {
+		t.Fatalf("expected trailing blank lines to be dropped, got %d root children", len(tr.Root.Children))
+	}
+	if len(tr.Root.Children[0].Children) != 1 || tr.Root.Children[0].Children[0].Text != "" {
+		t.Error("expected indented blank line to become an empty child")
+	}
+}
+
+func TestParseOutlineCRLF(t *testing.T) {
+	tr, _ := parseOutline("a\r\n  b\r\n")
+
+	if tr.Root.Children[0].Children[0].Text != "b" {
+		t.Errorf("expected b without carriage return, got %q", tr.Root.Children[0].Children[0].Text)
+	}
+}
+
+func TestParseOutlineCarriageReturns(t *testing.T) {
+	for _, text := range []string{"a\r  b\rc\r", "a\r\n  b\r\nc\r\n"} {
+		tr, _ := parseOutline(text)
+		if len(tr.Root.Children) != 2 || tr.Root.Children[0].Children[0].Text != "b" {
+			t.Errorf("%q: expected a with child b, then c", text)
+		}
+	}
+}
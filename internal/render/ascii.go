@@ -26,7 +26,10 @@ func DefaultStyle() Style {
 
 // Renderer renders tree structures to ASCII art
 type Renderer struct {
-	Style Style
+	Style            Style
+	IncludeCollapsed bool // Render children of collapsed nodes
+	CommentAlign     CommentAlign
+	CommentDelimiter string // Also align text after this delimiter as a comment
 }
 
 // NewRenderer creates a new ASCII renderer
@@ -34,31 +37,60 @@ func NewRenderer() *Renderer {
 	return &Renderer{Style: DefaultStyle()}
 }
 
+// Line is one rendered line of a tree
+type Line struct {
+	NodeID  string
+	Depth   int    // 1 for top-level nodes
+	Prefix  string // Branch drawing before the text
+	Text    string
+	Marker  string // Starts the comment
+	Comment string
+	Gap     int // Spaces between the text and the comment
+}
+
+// String returns the line as it appears in Render's output
+func (l Line) String() string {
+	return l.Prefix + l.Text + l.Suffix()
+}
+
+// Suffix returns the aligned comment that follows the text, if any
+func (l Line) Suffix() string {
+	if l.Marker == "" {
+		return ""
+	}
+	return strings.Repeat(" ", l.Gap) + l.Marker + l.Comment
+}
+
 // Render renders the entire tree to a string
 func (r *Renderer) Render(t *tree.Tree) string {
 	var sb strings.Builder
-	for i, child := range t.Root.Children {
-		isLast := i == len(t.Root.Children)-1
-		r.renderNode(&sb, child, "", isLast)
+	for _, line := range r.Lines(t) {
+		sb.WriteString(line.String() + "\n")
 	}
 	return sb.String()
 }
 
 // RenderLines renders the tree and returns individual lines
 func (r *Renderer) RenderLines(t *tree.Tree) []string {
-	output := r.Render(t)
-	if output == "" {
-		return []string{}
+	lines := []string{}
+	for _, line := range r.Lines(t) {
+		lines = append(lines, line.String())
 	}
-	lines := strings.Split(output, "\n")
-	// Remove trailing empty line
-	if len(lines) > 0 && lines[len(lines)-1] == "" {
-		lines = lines[:len(lines)-1]
+	return lines
+}
+
+// Lines renders the tree and returns each line with the node it shows
+func (r *Renderer) Lines(t *tree.Tree) []Line {
+	var lines []Line
+	for i, child := range t.Root.Children {
+		isLast := i == len(t.Root.Children)-1
+		r.renderNode(&lines, child, "", 1, isLast)
 	}
+	alignComments(lines, r.CommentAlign)
 	return lines
 }
 
-func (r *Renderer) renderNode(sb *strings.Builder, n *tree.Node, prefix string, isLast bool) {
+func (r *Renderer) renderNode(lines *[]Line, n *tree.Node, prefix string, depth int, isLast bool) {
 	// Choose branch character
 	branch := r.Style.Branch
 	if isLast {
@@ -70,7 +102,16 @@ func (r *Renderer) renderNode(sb *strings.Builder, n *tree.Node, prefix string,
 	if text == "" {
 		text = " "
 	}
-	sb.WriteString(prefix + branch + text + "\n")
+	line := Line{NodeID: n.ID, Depth: depth, Prefix: prefix + branch, Text: text}
+	if n.Comment != "" {
+		line.Marker = CommentMarker
+		line.Comment = n.Comment
+	} else if before, after, ok := splitComment(text, r.CommentDelimiter); ok {
+		line.Text = before
+		line.Marker = r.CommentDelimiter
+		line.Comment = after
+	}
+	*lines = append(*lines, line)
 
 	// Calculate prefix for children
 	childPrefix := prefix
@@ -81,10 +122,10 @@ func (r *Renderer) renderNode(sb *strings.Builder, n *tree.Node, prefix string,
 	}
 
 	// Render children if expanded
-	if n.Expanded {
+	if n.Expanded || r.IncludeCollapsed {
 		for i, child := range n.Children {
 			childIsLast := i == len(n.Children)-1
-			r.renderNode(sb, child, childPrefix, childIsLast)
+			r.renderNode(lines, child, childPrefix, depth+1, childIsLast)
 		}
 	}
 }
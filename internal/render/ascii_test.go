@@ -249,3 +249,51 @@ func TestRenderMixedStructure(t *testing.T) {
 		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
 	}
 }
+
+func TestRenderIncludeCollapsed(t *testing.T) {
+	tr := tree.NewTree()
+	tr.Root.Children = nil
+
+	parent := tree.NewNode("parent")
+	parent.AddChild(tree.NewNode("child"))
+	parent.Expanded = false
+	tr.Root.AddChild(parent)
+
+	r := NewRenderer()
+	r.IncludeCollapsed = true
+	output := r.Render(tr)
+
+	expected := "└── parent\n    └── child\n"
+	if output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestLines(t *testing.T) {
+	tr := sampleTree()
+	r := NewRenderer()
+
+	lines := r.Lines(tr)
+	expected := []Line{
+		{NodeID: tr.Root.Children[0].ID, Depth: 1, Prefix: "├── ", Text: "src"},
+		{NodeID: tr.Root.Children[0].Children[0].ID, Depth: 2, Prefix: "│   └── ", Text: "main.go"},
+		{NodeID: tr.Root.Children[1].ID, Depth: 1, Prefix: "└── ", Text: "README.md"},
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d", len(expected), len(lines))
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("line %d: expected %+v, got %+v", i, expected[i], line)
+		}
+	}
+
+	tr.Root.Children[0].Expanded = false
+	if lines := r.Lines(tr); len(lines) != 2 {
+		t.Errorf("expected 2 lines when collapsed, got %d", len(lines))
+	}
+	r.IncludeCollapsed = true
+	if lines := r.Lines(tr); len(lines) != 3 {
+		t.Errorf("expected 3 lines including collapsed, got %d", len(lines))
+	}
+}
@@ -0,0 +1,81 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// CommentMarker starts a node's comment in rendered output
+const CommentMarker = "# "
+
+// CommentAlign controls where comments are placed
+type CommentAlign int
+
+const (
+	// CommentAlignColumn lines up all comments in one column after the
+	// longest line
+	CommentAlignColumn CommentAlign = iota
+	// CommentAlignInline places each comment right after its node's text
+	CommentAlignInline
+)
+
+// commentAlignNames holds the names of the comment alignments
+var commentAlignNames = []string{"column", "inline"}
+
+// CommentAlignNames returns the names of the comment alignments
+func CommentAlignNames() []string {
+	return append([]string(nil), commentAlignNames...)
+}
+
+// ParseCommentAlign returns the comment alignment with the given name
+func ParseCommentAlign(name string) (CommentAlign, error) {
+	for i, n := range commentAlignNames {
+		if n == name {
+			return CommentAlign(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown comment alignment %q (available: %s)", name, strings.Join(commentAlignNames, ", "))
+}
+
+// commentGap is the smallest number of spaces before a comment
+const commentGap = 2
+
+// alignComments sets the gap before each line's comment
+func alignComments(lines []Line, align CommentAlign) {
+	column := 0
+	if align == CommentAlignColumn {
+		for _, line := range lines {
+			column = max(column, lineWidth(line))
+		}
+	}
+	for i, line := range lines {
+		if line.Marker != "" {
+			lines[i].Gap = max(column-lineWidth(line), 0) + commentGap
+		}
+	}
+}
+
+// lineWidth returns the display width of a line without its comment
+func lineWidth(line Line) int {
+	return ansi.StringWidth(line.Prefix + line.Text)
+}
+
+// splitComment splits text at the first delimiter that follows a space,
+// returning the text before it and the rest after the delimiter. It
+// returns ok false if there is no such delimiter or no text before it.
+func splitComment(text, delimiter string) (before, after string, ok bool) {
+	if delimiter == "" {
+		return text, "", false
+	}
+	i := strings.Index(text, " "+delimiter)
+	if i < 0 {
+		return text, "", false
+	}
+	before = strings.TrimRight(text[:i], " ")
+	if before == "" {
+		return text, "", false
+	}
+	return before, text[i+1+len(delimiter):], true
+}
@@ -0,0 +1,161 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// commentTree returns sampleTree with comments on main.go and README.md
+func commentTree() *tree.Tree {
+	tr := sampleTree()
+	tr.Root.Children[0].Children[0].Comment = "entry point"
+	tr.Root.Children[1].Comment = "docs"
+	return tr
+}
+
+func TestRenderCommentsInColumn(t *testing.T) {
+	expected := "├── src\n" +
+		"│   └── main.go  # entry point\n" +
+		"└── README.md    # docs\n"
+	if output := NewRenderer().Render(commentTree()); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestRenderCommentsInline(t *testing.T) {
+	r := NewRenderer()
+	r.CommentAlign = CommentAlignInline
+
+	expected := "├── src\n" +
+		"│   └── main.go  # entry point\n" +
+		"└── README.md  # docs\n"
+	if output := r.Render(commentTree()); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestRenderCommentColumnUsesLongestLine(t *testing.T) {
+	tr := commentTree()
+	tr.Root.Children[0].Text = "a-much-longer-directory-name"
+
+	// "├── a-much-longer-directory-name" is 32 characters wide
+	lines := NewRenderer().RenderLines(tr)
+	if expected := "└── README.md" + strings.Repeat(" ", 32-13+2) + "# docs"; lines[2] != expected {
+		t.Errorf("expected %q, got %q", expected, lines[2])
+	}
+}
+
+func TestLineSuffix(t *testing.T) {
+	line := Line{Prefix: "└── ", Text: "a", Marker: "# ", Comment: "note", Gap: 3}
+	if suffix := line.Suffix(); suffix != "   # note" {
+		t.Errorf("expected %q, got %q", "   # note", suffix)
+	}
+	if suffix := (Line{Text: "a"}).Suffix(); suffix != "" {
+		t.Errorf("expected no suffix without a comment, got %q", suffix)
+	}
+}
+
+func TestParseCommentAlign(t *testing.T) {
+	for _, name := range CommentAlignNames() {
+		if _, err := ParseCommentAlign(name); err != nil {
+			t.Errorf("alignment %q is listed but not parsed: %v", name, err)
+		}
+	}
+	if align, _ := ParseCommentAlign("inline"); align != CommentAlignInline {
+		t.Errorf("expected inline alignment, got %v", align)
+	}
+	if _, err := ParseCommentAlign("right"); err == nil {
+		t.Error("expected an error for an unknown alignment")
+	}
+}
+
+func TestExportComments(t *testing.T) {
+	tests := []struct {
+		exporter Exporter
+		expected string
+	}{
+		{OutlineExporter{}, "src\n  main.go  # entry point\nREADME.md  # docs\n"},
+		{MarkdownExporter{}, "- src\n  - main.go — entry point\n- README.md — docs\n"},
+		{YAMLExporter{}, "- text: src\n  children:\n    - text: main.go\n      comment: entry point\n- text: README.md\n  comment: docs\n"},
+	}
+	for _, tt := range tests {
+		if output := tt.exporter.Export(commentTree()); output != tt.expected {
+			t.Errorf("%T: expected:\n%s\ngot:\n%s", tt.exporter, tt.expected, output)
+		}
+	}
+}
+
+func TestSplitComment(t *testing.T) {
+	tests := []struct {
+		text, delimiter string
+		before, after   string
+		ok              bool
+	}{
+		{"name  -- description", "--", "name", " description", true},
+		{"name -- a -- b", "--", "name", " a -- b", true},
+		{"name--description", "--", "name--description", "", false},
+		{"-- only a comment", "--", "-- only a comment", "", false},
+		{"name  -- description", "", "name  -- description", "", false},
+	}
+	for _, tt := range tests {
+		before, after, ok := splitComment(tt.text, tt.delimiter)
+		if before != tt.before || after != tt.after || ok != tt.ok {
+			t.Errorf("splitComment(%q, %q) = %q, %q, %v; want %q, %q, %v",
+				tt.text, tt.delimiter, before, after, ok, tt.before, tt.after, tt.ok)
+		}
+	}
+}
+
+func TestRenderAlignsDelimitedComments(t *testing.T) {
+	tr := sampleTree()
+	tr.Root.Children[0].Children[0].Text = "main.go -- entry point"
+	tr.Root.Children[1].Text = "README.md    -- docs"
+
+	r := NewRenderer()
+	r.CommentDelimiter = "--"
+	expected := "├── src\n" +
+		"│   └── main.go  -- entry point\n" +
+		"└── README.md    -- docs\n"
+	if output := r.Render(tr); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+
+	r.CommentDelimiter = ""
+	if output := r.Render(tr); !strings.Contains(output, "main.go -- entry point") {
+		t.Errorf("expected text to be left alone without a delimiter, got:\n%s", output)
+	}
+}
+
+func TestRenderAlignsCommentsByDisplayWidth(t *testing.T) {
+	tr := sampleTree()
+	tr.Root.Children[0].Children[0].Text = "日本語.txt"
+	tr.Root.Children[0].Children[0].Comment = "wide"
+	tr.Root.Children[1].Comment = "narrow"
+
+	// "│   └── 日本語.txt" is 18 cells wide, "└── README.md" 13
+	lines := NewRenderer().RenderLines(tr)
+	if expected := "│   └── 日本語.txt  # wide"; lines[1] != expected {
+		t.Errorf("expected %q, got %q", expected, lines[1])
+	}
+	if expected := "└── README.md       # narrow"; lines[2] != expected {
+		t.Errorf("expected %q, got %q", expected, lines[2])
+	}
+}
+
+func TestRenderAlignsEmojiComments(t *testing.T) {
+	tr := sampleTree()
+	tr.Root.Children[0].Text = "🚀 app"
+	tr.Root.Children[0].Comment = "rocket"
+	tr.Root.Children[1].Comment = "docs"
+
+	// "├── 🚀 app" is 10 cells wide and "│   └── main.go" 15
+	lines := NewRenderer().RenderLines(tr)
+	if expected := "├── 🚀 app       # rocket"; lines[0] != expected {
+		t.Errorf("expected %q, got %q", expected, lines[0])
+	}
+	if expected := "└── README.md    # docs"; lines[2] != expected {
+		t.Errorf("expected %q, got %q", expected, lines[2])
+	}
+}
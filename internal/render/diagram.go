@@ -0,0 +1,119 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// diagramID returns a stable diagram identifier for n, derived from its ID
+func diagramID(n *tree.Node) string {
+	var sb strings.Builder
+	sb.WriteString("n")
+	for _, r := range n.ID {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+// DOTExporter exports trees as Graphviz DOT digraphs.
+// Collapsed children are always included.
+type DOTExporter struct{}
+
+// Export implements Exporter
+func (DOTExporter) Export(t *tree.Tree) string {
+	var sb strings.Builder
+	sb.WriteString("digraph tree {\n")
+	sb.WriteString("  node [shape=box];\n")
+	for _, child := range t.Root.Children {
+		writeDOTNode(&sb, child)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func writeDOTNode(sb *strings.Builder, n *tree.Node) {
+	sb.WriteString("  " + diagramID(n) + " [label=\"" + dotEscaper.Replace(n.Text) + "\"];\n")
+	for _, child := range n.Children {
+		sb.WriteString("  " + diagramID(n) + " -> " + diagramID(child) + ";\n")
+		writeDOTNode(sb, child)
+	}
+}
+
+// dotEscaper escapes text for a quoted DOT string
+var dotEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+)
+
+// MermaidExporter exports trees as Mermaid top-down flowcharts.
+// Collapsed children are always included.
+type MermaidExporter struct{}
+
+// Export implements Exporter
+func (MermaidExporter) Export(t *tree.Tree) string {
+	var sb strings.Builder
+	sb.WriteString("graph TD\n")
+	for _, child := range t.Root.Children {
+		writeMermaidNode(&sb, child)
+	}
+	return sb.String()
+}
+
+func writeMermaidNode(sb *strings.Builder, n *tree.Node) {
+	sb.WriteString("    " + diagramID(n) + mermaidLabel(n.Text) + "\n")
+	for _, child := range n.Children {
+		sb.WriteString("    " + diagramID(n) + " --> " + diagramID(child) + "\n")
+		writeMermaidNode(sb, child)
+	}
+}
+
+// MindmapExporter exports trees as Mermaid mindmaps. A mindmap has a single
+// root, so several top-level nodes are placed under a "." root.
+// Collapsed children are always included.
+type MindmapExporter struct{}
+
+// Export implements Exporter
+func (MindmapExporter) Export(t *tree.Tree) string {
+	var sb strings.Builder
+	sb.WriteString("mindmap\n")
+	if len(t.Root.Children) == 1 {
+		writeMindmapNode(&sb, t.Root.Children[0], 1)
+		return sb.String()
+	}
+	sb.WriteString("  root" + mermaidLabel(".") + "\n")
+	for _, child := range t.Root.Children {
+		writeMindmapNode(&sb, child, 2)
+	}
+	return sb.String()
+}
+
+func writeMindmapNode(sb *strings.Builder, n *tree.Node, depth int) {
+	sb.WriteString(strings.Repeat("  ", depth) + diagramID(n) + mermaidLabel(n.Text) + "\n")
+	for _, child := range n.Children {
+		writeMindmapNode(sb, child, depth+1)
+	}
+}
+
+// mermaidEscaper replaces characters that end or alter a quoted Mermaid
+// label with entity codes
+var mermaidEscaper = strings.NewReplacer(
+	"#", "#35;",
+	`"`, "#quot;",
+	"<", "#lt;",
+	">", "#gt;",
+	"\n", " ",
+)
+
+// mermaidLabel returns text as a quoted Mermaid node label
+func mermaidLabel(text string) string {
+	if text == "" {
+		text = " "
+	}
+	return `["` + mermaidEscaper.Replace(text) + `"]`
+}
@@ -0,0 +1,103 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// diagramTree returns sampleTree with fixed node IDs a, b and c
+func diagramTree() *tree.Tree {
+	tr := sampleTree()
+	tr.Root.Children[0].ID = "a"
+	tr.Root.Children[0].Children[0].ID = "b"
+	tr.Root.Children[1].ID = "c"
+	return tr
+}
+
+func TestDiagramID(t *testing.T) {
+	n := tree.NewNode("x")
+	n.ID = "1f-2e"
+	if id := diagramID(n); id != "n1f_2e" {
+		t.Errorf("expected n1f_2e, got %q", id)
+	}
+}
+
+func TestDOTExporter(t *testing.T) {
+	expected := `digraph tree {
+  node [shape=box];
+  na [label="src"];
+  na -> nb;
+  nb [label="main.go"];
+  nc [label="README.md"];
+}
+`
+	if output := (DOTExporter{}).Export(diagramTree()); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestDOTExporterEscapes(t *testing.T) {
+	tr := diagramTree()
+	tr.Root.Children[1].Text = `say "hi" \o/`
+
+	expected := `  nc [label="say \"hi\" \\o/"];` + "\n"
+	if output := (DOTExporter{}).Export(tr); !strings.Contains(output, expected) {
+		t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+	}
+}
+
+func TestMermaidExporter(t *testing.T) {
+	expected := `graph TD
+    na["src"]
+    na --> nb
+    nb["main.go"]
+    nc["README.md"]
+`
+	if output := (MermaidExporter{}).Export(diagramTree()); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestMermaidLabel(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"plain", `["plain"]`},
+		{"", `[" "]`},
+		{`a "b"`, `["a #quot;b#quot;"]`},
+		{"<br> #1", `["#lt;br#gt; #35;1"]`},
+	}
+	for _, tt := range tests {
+		if label := mermaidLabel(tt.text); label != tt.expected {
+			t.Errorf("mermaidLabel(%q) = %q, want %q", tt.text, label, tt.expected)
+		}
+	}
+}
+
+func TestMindmapExporter(t *testing.T) {
+	expected := `mindmap
+  root["."]
+    na["src"]
+      nb["main.go"]
+    nc["README.md"]
+`
+	if output := (MindmapExporter{}).Export(diagramTree()); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestMindmapExporterSingleRoot(t *testing.T) {
+	tr := diagramTree()
+	tr.Root.Children = tr.Root.Children[:1]
+
+	expected := `mindmap
+  na["src"]
+    nb["main.go"]
+`
+	if output := (MindmapExporter{}).Export(tr); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
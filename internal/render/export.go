@@ -0,0 +1,50 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// Exporter converts a tree to text in some format
+type Exporter interface {
+	Export(t *tree.Tree) string
+}
+
+// formatNames lists the export formats in menu order
+var formatNames = []string{"ascii", "outline", "markdown", "json", "yaml", "dot", "mermaid", "mindmap"}
+
+// FormatNames returns the names of the export formats
+func FormatNames() []string {
+	return append([]string(nil), formatNames...)
+}
+
+// LookupExporter returns the exporter for the named format.
+// The "ascii" format uses r, so it follows its style and folding.
+func LookupExporter(name string, r *Renderer) (Exporter, error) {
+	switch name {
+	case "", "ascii":
+		return r, nil
+	case "outline":
+		return OutlineExporter{}, nil
+	case "markdown":
+		return MarkdownExporter{}, nil
+	case "json":
+		return JSONExporter{}, nil
+	case "yaml":
+		return YAMLExporter{}, nil
+	case "dot":
+		return DOTExporter{}, nil
+	case "mermaid":
+		return MermaidExporter{}, nil
+	case "mindmap":
+		return MindmapExporter{}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q (available: %s)", name, strings.Join(formatNames, ", "))
+}
+
+// Export implements Exporter
+func (r *Renderer) Export(t *tree.Tree) string {
+	return r.Render(t)
+}
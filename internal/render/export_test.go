@@ -0,0 +1,58 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// sampleTree builds:
+// ├── src
+// │   └── main.go
+// └── README.md
+func sampleTree() *tree.Tree {
+	tr := tree.NewTree()
+	tr.Root.Children = nil
+	src := tree.NewNode("src")
+	src.AddChild(tree.NewNode("main.go"))
+	tr.Root.AddChild(src)
+	tr.Root.AddChild(tree.NewNode("README.md"))
+	return tr
+}
+
+func TestFormatNamesAreRegistered(t *testing.T) {
+	r := NewRenderer()
+	for _, name := range FormatNames() {
+		if _, err := LookupExporter(name, r); err != nil {
+			t.Errorf("format %q is listed but not registered", name)
+		}
+	}
+}
+
+func TestLookupExporterASCIIUsesRenderer(t *testing.T) {
+	r := NewRenderer()
+	r.Style, _ = LookupStyle("ascii")
+
+	e, err := LookupExporter("ascii", r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "|-- src\n|   `-- main.go\n`-- README.md\n"
+	if output := e.Export(sampleTree()); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestLookupExporterUnknown(t *testing.T) {
+	if _, err := LookupExporter("xml", NewRenderer()); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestOutlineExporter(t *testing.T) {
+	expected := "src\n  main.go\nREADME.md\n"
+	if output := (OutlineExporter{}).Export(sampleTree()); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
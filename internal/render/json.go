@@ -0,0 +1,40 @@
+package render
+
+import (
+	"encoding/json"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// jsonNode is the JSON representation of a node
+type jsonNode struct {
+	Text     string     `json:"text"`
+	Comment  string     `json:"comment,omitempty"`
+	Children []jsonNode `json:"children,omitempty"`
+}
+
+// JSONExporter exports trees as a JSON array of nodes.
+// Collapsed children are always included.
+type JSONExporter struct{}
+
+// Export implements Exporter
+func (JSONExporter) Export(t *tree.Tree) string {
+	data, err := json.MarshalIndent(toJSONNodes(t.Root.Children), "", "  ")
+	if err != nil {
+		// Marshalling strings and slices cannot fail
+		panic(err)
+	}
+	return string(data) + "\n"
+}
+
+func toJSONNodes(nodes []*tree.Node) []jsonNode {
+	result := make([]jsonNode, 0, len(nodes))
+	for _, n := range nodes {
+		result = append(result, jsonNode{
+			Text:     n.Text,
+			Comment:  n.Comment,
+			Children: toJSONNodes(n.Children),
+		})
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package render
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONExporter(t *testing.T) {
+	expected := `[
+  {
+    "text": "src",
+    "children": [
+      {
+        "text": "main.go"
+      }
+    ]
+  },
+  {
+    "text": "README.md"
+  }
+]
+`
+	if output := (JSONExporter{}).Export(sampleTree()); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestJSONExporterIsValid(t *testing.T) {
+	tr := sampleTree()
+	tr.Root.Children[0].Text = `quote " and \ backslash`
+
+	var nodes []jsonNode
+	if err := json.Unmarshal([]byte((JSONExporter{}).Export(tr)), &nodes); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if nodes[0].Text != tr.Root.Children[0].Text {
+		t.Errorf("expected text to round trip, got %q", nodes[0].Text)
+	}
+}
+
+func TestJSONExporterComment(t *testing.T) {
+	tr := sampleTree()
+	tr.Root.Children[1].Comment = "docs"
+
+	var nodes []jsonNode
+	if err := json.Unmarshal([]byte((JSONExporter{}).Export(tr)), &nodes); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if nodes[1].Comment != "docs" || nodes[0].Comment != "" {
+		t.Errorf("expected the comment on README.md only, got %+v", nodes)
+	}
+}
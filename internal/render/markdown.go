@@ -0,0 +1,59 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// MarkdownExporter exports trees as nested Markdown bullet lists.
+// Collapsed children are always included.
+type MarkdownExporter struct{}
+
+// Export implements Exporter
+func (MarkdownExporter) Export(t *tree.Tree) string {
+	var sb strings.Builder
+	for _, child := range t.Root.Children {
+		writeMarkdownNode(&sb, child, 0)
+	}
+	return sb.String()
+}
+
+func writeMarkdownNode(sb *strings.Builder, n *tree.Node, depth int) {
+	sb.WriteString(strings.Repeat("  ", depth) + "- " + escapeMarkdown(n.Text))
+	if n.Comment != "" {
+		sb.WriteString(" — " + escapeMarkdown(n.Comment))
+	}
+	sb.WriteString("\n")
+	for _, child := range n.Children {
+		writeMarkdownNode(sb, child, depth+1)
+	}
+}
+
+// markdownEscaper escapes characters that would change how a list item
+// is rendered
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"#", `\#`,
+)
+
+// listMarker matches a list marker at the start of text, which would make
+// the item a nested list: "-", "+" or a number followed by "." or ")"
+var listMarker = regexp.MustCompile(`^([-+]|\d{1,9}[.)])`)
+
+func escapeMarkdown(text string) string {
+	text = markdownEscaper.Replace(text)
+	if m := listMarker.FindString(text); m != "" {
+		// Escaping the last character leaves the marker as plain text
+		text = m[:len(m)-1] + `\` + text[len(m)-1:]
+	}
+	return text
+}
@@ -0,0 +1,47 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+func TestMarkdownExporter(t *testing.T) {
+	expected := "- src\n  - main.go\n- README.md\n"
+	if output := (MarkdownExporter{}).Export(sampleTree()); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestMarkdownExporterIncludesCollapsed(t *testing.T) {
+	tr := sampleTree()
+	tr.Root.Children[0].Expanded = false
+
+	expected := "- src\n  - main.go\n- README.md\n"
+	if output := (MarkdownExporter{}).Export(tr); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestMarkdownExporterEscapes(t *testing.T) {
+	tests := []struct {
+		text, expected string
+	}{
+		{"*bold* [link] #tag", "- \\*bold\\* \\[link\\] \\#tag\n"},
+		{"1. step one", "- 1\\. step one\n"},
+		{"12) step", "- 12\\) step\n"},
+		{"- dash", "- \\- dash\n"},
+		{"+ plus", "- \\+ plus\n"},
+		{"* star", "- \\* star\n"},
+		{"v1.2 - notes", "- v1.2 - notes\n"},
+	}
+	for _, tt := range tests {
+		tr := tree.NewTree()
+		tr.Root.Children = nil
+		tr.Root.AddChild(tree.NewNode(tt.text))
+
+		if output := (MarkdownExporter{}).Export(tr); output != tt.expected {
+			t.Errorf("%q: expected %q, got %q", tt.text, tt.expected, output)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// OutlineIndent is the indentation used for each level of an outline
+const OutlineIndent = "  "
+
+// OutlineExporter exports trees as indented plain-text outlines
+type OutlineExporter struct{}
+
+// Export implements Exporter
+func (OutlineExporter) Export(t *tree.Tree) string {
+	return Outline(t)
+}
+
+// Outline renders the tree as an indented plain-text outline.
+// Unlike Render, collapsed children are always included.
+func Outline(t *tree.Tree) string {
+	return OutlineWithGaps(t, nil)
+}
+
+// OutlineWithGaps is Outline with the number of spaces before the comments
+// of the nodes in gaps, by node ID
+func OutlineWithGaps(t *tree.Tree, gaps map[string]int) string {
+	var sb strings.Builder
+	for _, child := range t.Root.Children {
+		writeOutlineNode(&sb, child, 0, gaps)
+	}
+	return sb.String()
+}
+
+func writeOutlineNode(sb *strings.Builder, n *tree.Node, depth int, gaps map[string]int) {
+	sb.WriteString(strings.Repeat(OutlineIndent, depth) + n.Text)
+	if n.Comment != "" {
+		gap, ok := gaps[n.ID]
+		if !ok {
+			gap = commentGap
+		}
+		sb.WriteString(strings.Repeat(" ", gap) + CommentMarker + n.Comment)
+	}
+	sb.WriteString("\n")
+	for _, child := range n.Children {
+		writeOutlineNode(sb, child, depth+1, gaps)
+	}
+}
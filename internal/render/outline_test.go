@@ -0,0 +1,51 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+func TestOutline(t *testing.T) {
+	tr := tree.NewTree()
+	tr.Root.Children = nil
+
+	src := tree.NewNode("src")
+	src.AddChild(tree.NewNode("main.go"))
+	tr.Root.AddChild(src)
+	tr.Root.AddChild(tree.NewNode("README.md"))
+
+	expected := "src\n  main.go\nREADME.md\n"
+	if output := Outline(tr); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestOutlineIncludesCollapsed(t *testing.T) {
+	tr := tree.NewTree()
+	tr.Root.Children = nil
+
+	parent := tree.NewNode("parent")
+	parent.AddChild(tree.NewNode("child"))
+	parent.Expanded = false
+	tr.Root.AddChild(parent)
+
+	expected := "parent\n  child\n"
+	if output := Outline(tr); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestOutlineEmptyText(t *testing.T) {
+	tr := tree.NewTree()
+	tr.Root.Children = nil
+
+	parent := tree.NewNode("parent")
+	parent.AddChild(tree.NewNode(""))
+	tr.Root.AddChild(parent)
+
+	expected := "parent\n  \n"
+	if output := Outline(tr); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
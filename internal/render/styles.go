@@ -0,0 +1,87 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+// styles holds the built-in styles by name
+var styles = map[string]Style{
+	"default": DefaultStyle(),
+	"ascii": {
+		Branch:     "|-- ",
+		LastBranch: "`-- ",
+		Vertical:   "|   ",
+		Space:      "    ",
+	},
+	"rounded": {
+		Branch:     "├── ",
+		LastBranch: "╰── ",
+		Vertical:   "│   ",
+		Space:      "    ",
+	},
+	"heavy": {
+		Branch:     "┣━━ ",
+		LastBranch: "┗━━ ",
+		Vertical:   "┃   ",
+		Space:      "    ",
+	},
+	"double": {
+		Branch:     "╠══ ",
+		LastBranch: "╚══ ",
+		Vertical:   "║   ",
+		Space:      "    ",
+	},
+	"compact": {
+		Branch:     "├ ",
+		LastBranch: "└ ",
+		Vertical:   "│ ",
+		Space:      "  ",
+	},
+	"markdown": {
+		Branch:     "- ",
+		LastBranch: "- ",
+		Vertical:   "  ",
+		Space:      "  ",
+	},
+}
+
+// styleNames lists the built-in styles in cycling order
+var styleNames = []string{"default", "ascii", "rounded", "heavy", "double", "compact", "markdown"}
+
+// StyleNames returns the names of the built-in styles
+func StyleNames() []string {
+	return append([]string(nil), styleNames...)
+}
+
+// LookupStyle returns the style with the given name
+func LookupStyle(name string) (Style, error) {
+	if name == "" {
+		return DefaultStyle(), nil
+	}
+	if style, ok := styles[name]; ok {
+		return style, nil
+	}
+	return Style{}, fmt.Errorf("unknown style %q (available: %s)", name, strings.Join(styleNames, ", "))
+}
+
+// StyleName returns the name of a built-in style, or "" for a custom one
+func StyleName(style Style) string {
+	for _, name := range styleNames {
+		if styles[name] == style {
+			return name
+		}
+	}
+	return ""
+}
+
+// NextStyle returns the built-in style after the given one, wrapping around
+func NextStyle(style Style) Style {
+	name := StyleName(style)
+	for i, n := range styleNames {
+		if n == name {
+			return styles[styleNames[(i+1)%len(styleNames)]]
+		}
+	}
+	return DefaultStyle()
+}
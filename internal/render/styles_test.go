@@ -0,0 +1,90 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+func TestLookupStyle(t *testing.T) {
+	style, err := LookupStyle("default")
+	if err != nil || style != DefaultStyle() {
+		t.Errorf("expected default style, got %+v, %v", style, err)
+	}
+
+	style, err = LookupStyle("")
+	if err != nil || style != DefaultStyle() {
+		t.Errorf("expected empty name to mean default, got %+v, %v", style, err)
+	}
+
+	if _, err := LookupStyle("nope"); err == nil {
+		t.Error("expected error for unknown style")
+	}
+}
+
+func TestStyleNamesAreRegistered(t *testing.T) {
+	for _, name := range StyleNames() {
+		if _, err := LookupStyle(name); err != nil {
+			t.Errorf("style %q is listed but not registered", name)
+		}
+	}
+	if len(StyleNames()) != len(styles) {
+		t.Errorf("expected %d listed styles, got %d", len(styles), len(StyleNames()))
+	}
+}
+
+func TestStyleName(t *testing.T) {
+	style, _ := LookupStyle("heavy")
+
+	if name := StyleName(style); name != "heavy" {
+		t.Errorf("expected 'heavy', got %q", name)
+	}
+	if name := StyleName(Style{Branch: "x"}); name != "" {
+		t.Errorf("expected custom style to have no name, got %q", name)
+	}
+}
+
+func TestNextStyle(t *testing.T) {
+	style := DefaultStyle()
+	seen := make(map[string]bool)
+	for range StyleNames() {
+		seen[StyleName(style)] = true
+		style = NextStyle(style)
+	}
+
+	if style != DefaultStyle() {
+		t.Error("expected cycling to wrap around to the default style")
+	}
+	if len(seen) != len(StyleNames()) {
+		t.Errorf("expected to visit all %d styles, visited %d", len(StyleNames()), len(seen))
+	}
+	if NextStyle(Style{Branch: "x"}) != DefaultStyle() {
+		t.Error("expected custom style to cycle to default")
+	}
+}
+
+func TestRenderStyles(t *testing.T) {
+	tr := tree.NewTree()
+	tr.Root.Children = nil
+	parent := tree.NewNode("a")
+	parent.AddChild(tree.NewNode("a1"))
+	tr.Root.AddChild(parent)
+	tr.Root.AddChild(tree.NewNode("b"))
+
+	tests := map[string]string{
+		"ascii":    "|-- a\n|   `-- a1\n`-- b\n",
+		"rounded":  "├── a\n│   ╰── a1\n╰── b\n",
+		"heavy":    "┣━━ a\n┃   ┗━━ a1\n┗━━ b\n",
+		"double":   "╠══ a\n║   ╚══ a1\n╚══ b\n",
+		"compact":  "├ a\n│ └ a1\n└ b\n",
+		"markdown": "- a\n  - a1\n- b\n",
+	}
+
+	for name, expected := range tests {
+		r := NewRenderer()
+		r.Style, _ = LookupStyle(name)
+		if output := r.Render(tr); output != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, output)
+		}
+	}
+}
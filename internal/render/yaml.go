@@ -0,0 +1,74 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// YAMLExporter exports trees as a YAML sequence of nodes with the same
+// shape as JSONExporter. Collapsed children are always included.
+type YAMLExporter struct{}
+
+// Export implements Exporter
+func (YAMLExporter) Export(t *tree.Tree) string {
+	if len(t.Root.Children) == 0 {
+		return "[]\n"
+	}
+	var sb strings.Builder
+	writeYAMLNodes(&sb, t.Root.Children, "")
+	return sb.String()
+}
+
+func writeYAMLNodes(sb *strings.Builder, nodes []*tree.Node, indent string) {
+	for _, n := range nodes {
+		sb.WriteString(indent + "- text: " + yamlString(n.Text) + "\n")
+		if n.Comment != "" {
+			sb.WriteString(indent + "  comment: " + yamlString(n.Comment) + "\n")
+		}
+		if len(n.Children) > 0 {
+			sb.WriteString(indent + "  children:\n")
+			writeYAMLNodes(sb, n.Children, indent+"    ")
+		}
+	}
+}
+
+// yamlReserved holds plain scalars that YAML would not read as strings
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+	"null": true, "~": true, "y": true, "n": true,
+}
+
+// yamlString returns text as a YAML scalar, double-quoting it unless it is
+// unambiguously a plain string
+func yamlString(text string) string {
+	if isPlainYAML(text) {
+		return text
+	}
+	return strconv.Quote(text)
+}
+
+func isPlainYAML(text string) bool {
+	if text == "" || yamlReserved[strings.ToLower(text)] {
+		return false
+	}
+	for i, r := range text {
+		switch {
+		case unicode.IsLetter(r), r == '_', r == '/':
+		case unicode.IsDigit(r), r == '.', r == '-', r == '(', r == ')':
+			// Leading digits, dots or dashes could read as numbers or indicators
+			if i == 0 {
+				return false
+			}
+		case r == ' ':
+			if i == len(text)-1 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+func TestYAMLExporter(t *testing.T) {
+	expected := `- text: src
+  children:
+    - text: main.go
+- text: README.md
+`
+	if output := (YAMLExporter{}).Export(sampleTree()); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestYAMLExporterEmpty(t *testing.T) {
+	tr := tree.NewTree()
+	tr.Root.Children = nil
+
+	if output := (YAMLExporter{}).Export(tr); output != "[]\n" {
+		t.Errorf("expected empty sequence, got %q", output)
+	}
+}
+
+func TestYAMLString(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"main.go", "main.go"},
+		{"src/components", "src/components"},
+		{"日本語.txt", "日本語.txt"},
+		{"my file (copy)", "my file (copy)"},
+		{"", `""`},
+		{"yes", `"yes"`},
+		{"No", `"No"`},
+		{"123", `"123"`},
+		{".env", `".env"`},
+		{"- item", `"- item"`},
+		{"key: value", `"key: value"`},
+		{"# comment", `"# comment"`},
+		{"trailing ", `"trailing "`},
+		{`say "hi"`, `"say \"hi\""`},
+	}
+
+	for _, tt := range tests {
+		if result := yamlString(tt.text); result != tt.expected {
+			t.Errorf("yamlString(%q) = %s, expected %s", tt.text, result, tt.expected)
+		}
+	}
+}
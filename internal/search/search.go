@@ -0,0 +1,64 @@
+// Package search finds nodes whose text matches a query.
+package search
+
+import (
+	"regexp"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// Options control how a query is matched
+type Options struct {
+	Regex      bool // Treat the query as a regular expression
+	IgnoreCase bool
+}
+
+// Matcher matches node text against a compiled query
+type Matcher struct {
+	re *regexp.Regexp
+}
+
+// Compile returns a matcher for query
+func Compile(query string, opts Options) (*Matcher, error) {
+	pattern := query
+	if !opts.Regex {
+		pattern = regexp.QuoteMeta(query)
+	}
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &Matcher{re: re}, nil
+}
+
+// Ranges returns the byte ranges of the matches in text.
+// Empty matches are skipped, so an empty query matches nothing.
+func (m *Matcher) Ranges(text string) [][]int {
+	var ranges [][]int
+	for _, r := range m.re.FindAllStringIndex(text, -1) {
+		if r[1] > r[0] {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
+
+// Match reports whether text contains a match
+func (m *Matcher) Match(text string) bool {
+	return len(m.Ranges(text)) > 0
+}
+
+// Nodes returns the matching nodes of t in order, including those
+// inside collapsed nodes
+func (m *Matcher) Nodes(t *tree.Tree) []*tree.Node {
+	var result []*tree.Node
+	for _, n := range t.Flatten() {
+		if m.Match(n.Text) {
+			result = append(result, n)
+		}
+	}
+	return result
+}
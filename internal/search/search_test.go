@@ -0,0 +1,61 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+func TestRanges(t *testing.T) {
+	tests := []struct {
+		query    string
+		opts     Options
+		text     string
+		expected [][]int
+	}{
+		{"go", Options{}, "go.mod and main.go", [][]int{{0, 2}, {16, 18}}},
+		{"Go", Options{}, "go.mod", nil},
+		{"Go", Options{IgnoreCase: true}, "go.mod", [][]int{{0, 2}}},
+		{"a.c", Options{}, "abc a.c", [][]int{{4, 7}}},
+		{"a.c", Options{Regex: true}, "abc a.c", [][]int{{0, 3}, {4, 7}}},
+		{"x*", Options{Regex: true}, "abc", nil},
+		{"", Options{}, "abc", nil},
+	}
+	for _, tt := range tests {
+		m, err := Compile(tt.query, tt.opts)
+		if err != nil {
+			t.Fatalf("Compile(%q) failed: %v", tt.query, err)
+		}
+		if ranges := m.Ranges(tt.text); !reflect.DeepEqual(ranges, tt.expected) {
+			t.Errorf("Ranges(%q) for %q = %v, want %v", tt.text, tt.query, ranges, tt.expected)
+		}
+	}
+}
+
+func TestCompileInvalidRegex(t *testing.T) {
+	if _, err := Compile("(", Options{Regex: true}); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+	if _, err := Compile("(", Options{}); err != nil {
+		t.Errorf("expected a plain query to compile, got %v", err)
+	}
+}
+
+func TestNodes(t *testing.T) {
+	tr := tree.NewTree()
+	tr.Root.Children = nil
+	src := tree.NewNode("src")
+	main := tree.NewNode("main.go")
+	src.AddChild(main)
+	src.Expanded = false
+	tr.Root.AddChild(src)
+	tr.Root.AddChild(tree.NewNode("README.md"))
+	tr.Root.AddChild(tree.NewNode("go.mod"))
+
+	m, _ := Compile("go", Options{})
+	nodes := m.Nodes(tr)
+	if len(nodes) != 2 || nodes[0] != main || nodes[1].Text != "go.mod" {
+		t.Errorf("expected main.go and go.mod, got %v", nodes)
+	}
+}
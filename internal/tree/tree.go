@@ -6,6 +6,7 @@ import "github.com/google/uuid"
 type Node struct {
 	ID       string
 	Text     string
+	Comment  string // Annotation shown beside the text
 	Children []*Node
 	Parent   *Node
 	Expanded bool
@@ -82,6 +83,64 @@ func (n *Node) IsLastChild() bool {
 	return n.Index() == len(n.Parent.Children)-1
 }
 
+// DescendantCount returns the number of nodes below this node
+func (n *Node) DescendantCount() int {
+	count := len(n.Children)
+	for _, c := range n.Children {
+		count += c.DescendantCount()
+	}
+	return count
+}
+
+// VisibleAncestor returns the node itself if all its ancestors are expanded,
+// otherwise the outermost collapsed ancestor that hides it
+func (n *Node) VisibleAncestor() *Node {
+	visible := n
+	for p := n.Parent; p != nil && p.Parent != nil; p = p.Parent {
+		if !p.Expanded {
+			visible = p
+		}
+	}
+	return visible
+}
+
+// Contains reports whether other is n or one of its descendants
+func (n *Node) Contains(other *Node) bool {
+	for p := other; p != nil; p = p.Parent {
+		if p == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Copy returns a deep copy of the node and its descendants with new IDs.
+// The copy has no parent.
+func (n *Node) Copy() *Node {
+	c := NewNode(n.Text)
+	c.Comment = n.Comment
+	c.Expanded = n.Expanded
+	for _, child := range n.Children {
+		c.AddChild(child.Copy())
+	}
+	return c
+}
+
+// clone returns a deep copy of the node and its descendants, keeping IDs
+func (n *Node) clone() *Node {
+	c := &Node{
+		ID:       n.ID,
+		Text:     n.Text,
+		Comment:  n.Comment,
+		Children: make([]*Node, 0, len(n.Children)),
+		Expanded: n.Expanded,
+	}
+	for _, child := range n.Children {
+		c.AddChild(child.clone())
+	}
+	return c
+}
+
 // Tree represents the entire tree structure
 type Tree struct {
 	Root *Node
@@ -94,6 +153,79 @@ func NewTree() *Tree {
 	return &Tree{Root: root}
 }
 
+// Clone returns a deep copy of the tree. Nodes keep their IDs.
+func (t *Tree) Clone() *Tree {
+	return &Tree{Root: t.Root.clone()}
+}
+
+// Filter returns a copy of the tree holding only the nodes that match and
+// their ancestors, like tree -P. The copy is fully expanded and nodes keep
+// their IDs.
+func (t *Tree) Filter(match func(*Node) bool) *Tree {
+	var filter func(n *Node) *Node
+	filter = func(n *Node) *Node {
+		c := &Node{ID: n.ID, Text: n.Text, Comment: n.Comment, Expanded: true}
+		for _, child := range n.Children {
+			if fc := filter(child); fc != nil {
+				c.AddChild(fc)
+			}
+		}
+		if len(c.Children) == 0 && !match(n) {
+			return nil
+		}
+		return c
+	}
+	root := &Node{ID: t.Root.ID, Text: t.Root.Text, Expanded: true}
+	for _, child := range t.Root.Children {
+		if fc := filter(child); fc != nil {
+			root.AddChild(fc)
+		}
+	}
+	return &Tree{Root: root}
+}
+
+// FlattenMatching returns the nodes that match and their ancestors in
+// order, including those inside collapsed nodes
+func (t *Tree) FlattenMatching(match func(*Node) bool) []*Node {
+	var result []*Node
+	var walk func(n *Node) bool
+	walk = func(n *Node) bool {
+		i := len(result)
+		result = append(result, n)
+		found := match(n)
+		for _, c := range n.Children {
+			if walk(c) {
+				found = true
+			}
+		}
+		if !found {
+			result = result[:i]
+		}
+		return found
+	}
+	for _, c := range t.Root.Children {
+		walk(c)
+	}
+	return result
+}
+
+// Find returns the node with the given ID, or nil
+func (t *Tree) Find(id string) *Node {
+	var find func(n *Node) *Node
+	find = func(n *Node) *Node {
+		if n.ID == id {
+			return n
+		}
+		for _, c := range n.Children {
+			if found := find(c); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(t.Root)
+}
+
 // FlattenVisible returns all visible nodes in order (for display)
 func (t *Tree) FlattenVisible() []*Node {
 	var result []*Node
@@ -101,6 +233,21 @@ func (t *Tree) FlattenVisible() []*Node {
 	return result
 }
 
+// Flatten returns all nodes in order, including the children of
+// collapsed nodes
+func (t *Tree) Flatten() []*Node {
+	var result []*Node
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, c := range n.Children {
+			result = append(result, c)
+			walk(c)
+		}
+	}
+	walk(t.Root)
+	return result
+}
+
 func (t *Tree) flattenNode(n *Node, result *[]*Node, skipRoot bool) {
 	if !skipRoot {
 		*result = append(*result, n)
@@ -112,6 +259,46 @@ func (t *Tree) flattenNode(n *Node, result *[]*Node, skipRoot bool) {
 	}
 }
 
+// Collapse hides the children of a node
+func (t *Tree) Collapse(n *Node) bool {
+	if len(n.Children) == 0 || !n.Expanded {
+		return false
+	}
+	n.Expanded = false
+	return true
+}
+
+// Expand shows the children of a node
+func (t *Tree) Expand(n *Node) bool {
+	if len(n.Children) == 0 || n.Expanded {
+		return false
+	}
+	n.Expanded = true
+	return true
+}
+
+// Reveal expands the ancestors of a node so that it is visible
+func (t *Tree) Reveal(n *Node) {
+	for p := n.Parent; p != nil && p != t.Root; p = p.Parent {
+		p.Expanded = true
+	}
+}
+
+// FoldToDepth expands nodes shallower than depth and collapses the rest,
+// so that depth levels are visible. A depth of 0 expands everything.
+func (t *Tree) FoldToDepth(depth int) {
+	var walk func(n *Node, d int)
+	walk = func(n *Node, d int) {
+		n.Expanded = depth == 0 || d < depth
+		for _, c := range n.Children {
+			walk(c, d+1)
+		}
+	}
+	for _, c := range t.Root.Children {
+		walk(c, 1)
+	}
+}
+
 // Indent moves a node to be a child of its previous sibling
 func (t *Tree) Indent(n *Node) bool {
 	if n.Parent == nil {
@@ -140,6 +327,89 @@ func (t *Tree) Unindent(n *Node) bool {
 	return true
 }
 
+// MoveUp swaps a node with its previous sibling. The first child of a node
+// moves to the end of its parent's previous sibling, or out in front of its
+// parent if there is none.
+func (t *Tree) MoveUp(n *Node) bool {
+	parent := n.Parent
+	if parent == nil {
+		return false
+	}
+	idx := n.Index()
+	if idx > 0 {
+		parent.Children[idx-1], parent.Children[idx] = parent.Children[idx], parent.Children[idx-1]
+		return true
+	}
+	if parent.Parent == nil {
+		return false
+	}
+	parentIdx := parent.Index()
+	parent.RemoveChild(n)
+	if parentIdx > 0 {
+		prev := parent.Parent.Children[parentIdx-1]
+		prev.AddChild(n)
+		prev.Expanded = true
+	} else {
+		parent.Parent.AddChildAt(n, parentIdx)
+	}
+	return true
+}
+
+// MoveDown swaps a node with its next sibling. The last child of a node
+// moves to the start of its parent's next sibling, or out after its parent
+// if there is none.
+func (t *Tree) MoveDown(n *Node) bool {
+	parent := n.Parent
+	if parent == nil {
+		return false
+	}
+	idx := n.Index()
+	if idx < len(parent.Children)-1 {
+		parent.Children[idx], parent.Children[idx+1] = parent.Children[idx+1], parent.Children[idx]
+		return true
+	}
+	if parent.Parent == nil {
+		return false
+	}
+	parentIdx := parent.Index()
+	parent.RemoveChild(n)
+	if parentIdx < len(parent.Parent.Children)-1 {
+		t.InsertChild(parent.Parent.Children[parentIdx+1], n)
+	} else {
+		parent.Parent.AddChildAt(n, parentIdx+1)
+	}
+	return true
+}
+
+// MoveAfter moves a node and its subtree to just after target.
+// A node can't be moved next to itself or into its own subtree.
+func (t *Tree) MoveAfter(n, target *Node) bool {
+	if n.Parent == nil || target.Parent == nil || n.Contains(target) {
+		return false
+	}
+	if n.Parent == target.Parent && n.Index() == target.Index()+1 {
+		return false
+	}
+	n.Parent.RemoveChild(n)
+	t.InsertAfter(target, n)
+	return true
+}
+
+// MoveInto moves a node and its subtree to the end of target's children
+// and expands target. A node can't be moved into its own subtree.
+func (t *Tree) MoveInto(n, target *Node) bool {
+	if n.Parent == nil || n.Contains(target) {
+		return false
+	}
+	if n.Parent == target && n.IsLastChild() {
+		return false
+	}
+	n.Parent.RemoveChild(n)
+	target.AddChild(n)
+	target.Expanded = true
+	return true
+}
+
 // InsertAfter inserts a new node after the given node
 func (t *Tree) InsertAfter(n *Node, newNode *Node) {
 	if n.Parent == nil {
@@ -149,6 +419,14 @@ func (t *Tree) InsertAfter(n *Node, newNode *Node) {
 	n.Parent.AddChildAt(newNode, idx+1)
 }
 
+// InsertBefore inserts a new node before the given node
+func (t *Tree) InsertBefore(n *Node, newNode *Node) {
+	if n.Parent == nil {
+		return
+	}
+	n.Parent.AddChildAt(newNode, n.Index())
+}
+
 // InsertChild inserts a new node as the first child
 func (t *Tree) InsertChild(n *Node, newNode *Node) {
 	n.AddChildAt(newNode, 0)
@@ -166,6 +444,7 @@ func (t *Tree) Delete(n *Node) *Node {
 	// Don't delete if it's the only node
 	if parent == t.Root && len(parent.Children) == 1 {
 		n.Text = ""
+		n.Comment = ""
 		return n
 	}
 
@@ -1,6 +1,7 @@
 package tree
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -213,6 +214,149 @@ func TestFlattenVisibleCollapsed(t *testing.T) {
 	}
 }
 
+func TestFlattenIncludesCollapsed(t *testing.T) {
+	tree := NewTree()
+	tree.Root.Children = nil
+
+	child1 := NewNode("child1")
+	child2 := NewNode("child2")
+	grandchild := NewNode("grandchild")
+
+	tree.Root.AddChild(child1)
+	tree.Root.AddChild(child2)
+	child1.AddChild(grandchild)
+	child1.Expanded = false
+
+	nodes := tree.Flatten()
+	if len(nodes) != 3 {
+		t.Fatalf("expected 3 nodes, got %d", len(nodes))
+	}
+	if nodes[1] != grandchild {
+		t.Errorf("expected second node 'grandchild', got '%s'", nodes[1].Text)
+	}
+}
+
+func TestReveal(t *testing.T) {
+	tree := NewTree()
+	tree.Root.Children = nil
+
+	a := NewNode("a")
+	b := NewNode("b")
+	c := NewNode("c")
+	tree.Root.AddChild(a)
+	a.AddChild(b)
+	b.AddChild(c)
+	tree.FoldToDepth(1)
+
+	tree.Reveal(c)
+	if len(tree.FlattenVisible()) != 3 {
+		t.Errorf("expected 3 visible nodes after reveal, got %d", len(tree.FlattenVisible()))
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tree := buildFilterTree()
+	filtered := tree.Filter(func(n *Node) bool { return strings.HasSuffix(n.Text, ".go") })
+
+	expected := "src\n  main.go\n  ui\n    view.go\n"
+	if got := outline(filtered); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+	if len(filtered.FlattenVisible()) != 4 {
+		t.Error("expected the filtered tree to be fully expanded")
+	}
+	if outline(tree) == expected {
+		t.Error("expected the original tree to be unchanged")
+	}
+}
+
+func TestFlattenMatching(t *testing.T) {
+	tree := buildFilterTree()
+	nodes := tree.FlattenMatching(func(n *Node) bool { return strings.HasSuffix(n.Text, ".go") })
+
+	var texts []string
+	for _, n := range nodes {
+		texts = append(texts, n.Text)
+	}
+	if got := strings.Join(texts, ","); got != "src,main.go,ui,view.go" {
+		t.Errorf("expected src,main.go,ui,view.go, got %s", got)
+	}
+	if nodes[0] != tree.Root.Children[0] {
+		t.Error("expected the tree's own nodes")
+	}
+}
+
+// buildFilterTree builds src (with main.go, notes.txt and a collapsed ui
+// holding view.go) followed by README.md
+func buildFilterTree() *Tree {
+	tree := NewTree()
+	tree.Root.Children = nil
+	src := NewNode("src")
+	src.AddChild(NewNode("main.go"))
+	src.AddChild(NewNode("notes.txt"))
+	ui := NewNode("ui")
+	ui.AddChild(NewNode("view.go"))
+	ui.Expanded = false
+	src.AddChild(ui)
+	tree.Root.AddChild(src)
+	tree.Root.AddChild(NewNode("README.md"))
+	return tree
+}
+
+func TestContains(t *testing.T) {
+	_, nodes := buildTree()
+	b := nodes["b"]
+	if !b.Contains(b) || !b.Contains(nodes["b1"]) {
+		t.Error("expected a node to contain itself and its children")
+	}
+	if b.Contains(nodes["a"]) || nodes["b1"].Contains(b) {
+		t.Error("expected a node not to contain its siblings or parent")
+	}
+}
+
+func TestMoveAfter(t *testing.T) {
+	tree := buildFilterTree()
+	src, readme := tree.Root.Children[0], tree.Root.Children[1]
+	main := src.Children[0]
+
+	if !tree.MoveAfter(readme, main) {
+		t.Fatal("expected README.md to move")
+	}
+	expected := "src\n  main.go\n  README.md\n  notes.txt\n  ui\n    view.go\n"
+	if got := outline(tree); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+	if tree.MoveAfter(readme, main) {
+		t.Error("expected moving to the same place to do nothing")
+	}
+	if tree.MoveAfter(src, main) || tree.MoveAfter(src, src) {
+		t.Error("expected a node not to move into its own subtree")
+	}
+}
+
+func TestMoveInto(t *testing.T) {
+	tree := buildFilterTree()
+	src, readme := tree.Root.Children[0], tree.Root.Children[1]
+	ui := src.Children[2]
+
+	if !tree.MoveInto(readme, ui) {
+		t.Fatal("expected README.md to move")
+	}
+	if !ui.Expanded {
+		t.Error("expected the target to be expanded")
+	}
+	expected := "src\n  main.go\n  notes.txt\n  ui\n    view.go\n    README.md\n"
+	if got := outline(tree); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+	if tree.MoveInto(readme, ui) {
+		t.Error("expected moving to the same place to do nothing")
+	}
+	if tree.MoveInto(src, ui) {
+		t.Error("expected a node not to move into its own subtree")
+	}
+}
+
 func TestIndent(t *testing.T) {
 	tree := NewTree()
 	tree.Root.Children = nil
@@ -341,6 +485,7 @@ func TestDeleteOnlyChild(t *testing.T) {
 
 	child := NewNode("child")
 	child.Text = "some text"
+	child.Comment = "note"
 	tree.Root.AddChild(child)
 
 	nextFocus := tree.Delete(child)
@@ -349,8 +494,8 @@ func TestDeleteOnlyChild(t *testing.T) {
 	if len(tree.Root.Children) != 1 {
 		t.Errorf("expected 1 child (not deleted), got %d", len(tree.Root.Children))
 	}
-	if child.Text != "" {
-		t.Errorf("expected empty text, got '%s'", child.Text)
+	if child.Text != "" || child.Comment != "" {
+		t.Errorf("expected empty text and comment, got '%s' and '%s'", child.Text, child.Comment)
 	}
 	if nextFocus != child {
 		t.Error("expected next focus to be the same child")
@@ -373,3 +518,301 @@ func TestDeleteReturnsParent(t *testing.T) {
 		t.Error("expected next focus to be parent when last child deleted")
 	}
 }
+
+func TestDescendantCount(t *testing.T) {
+	parent := NewNode("parent")
+	child := NewNode("child")
+	parent.AddChild(child)
+	parent.AddChild(NewNode("child2"))
+	child.AddChild(NewNode("grandchild"))
+
+	if count := parent.DescendantCount(); count != 3 {
+		t.Errorf("expected 3 descendants, got %d", count)
+	}
+	if count := child.DescendantCount(); count != 1 {
+		t.Errorf("expected 1 descendant, got %d", count)
+	}
+}
+
+func TestVisibleAncestor(t *testing.T) {
+	tree := NewTree()
+	tree.Root.Children = nil
+
+	a := NewNode("a")
+	b := NewNode("b")
+	c := NewNode("c")
+	tree.Root.AddChild(a)
+	a.AddChild(b)
+	b.AddChild(c)
+
+	if c.VisibleAncestor() != c {
+		t.Error("expected visible node to return itself")
+	}
+
+	b.Expanded = false
+	if c.VisibleAncestor() != b {
+		t.Error("expected collapsed parent")
+	}
+
+	a.Expanded = false
+	if c.VisibleAncestor() != a {
+		t.Error("expected outermost collapsed ancestor")
+	}
+}
+
+func TestCollapseExpand(t *testing.T) {
+	tree := NewTree()
+	tree.Root.Children = nil
+
+	parent := NewNode("parent")
+	leaf := NewNode("leaf")
+	tree.Root.AddChild(parent)
+	parent.AddChild(leaf)
+
+	if !tree.Collapse(parent) || parent.Expanded {
+		t.Error("expected parent to collapse")
+	}
+	if tree.Collapse(parent) {
+		t.Error("expected collapsing a collapsed node to return false")
+	}
+	if tree.Collapse(leaf) {
+		t.Error("expected collapsing a leaf to return false")
+	}
+	if !tree.Expand(parent) || !parent.Expanded {
+		t.Error("expected parent to expand")
+	}
+	if tree.Expand(parent) {
+		t.Error("expected expanding an expanded node to return false")
+	}
+}
+
+func TestFoldToDepth(t *testing.T) {
+	tree := NewTree()
+	tree.Root.Children = nil
+
+	a := NewNode("a")
+	b := NewNode("b")
+	c := NewNode("c")
+	tree.Root.AddChild(a)
+	a.AddChild(b)
+	b.AddChild(c)
+
+	tree.FoldToDepth(1)
+	if len(tree.FlattenVisible()) != 1 {
+		t.Errorf("expected 1 visible node at depth 1, got %d", len(tree.FlattenVisible()))
+	}
+
+	tree.FoldToDepth(2)
+	if len(tree.FlattenVisible()) != 2 {
+		t.Errorf("expected 2 visible nodes at depth 2, got %d", len(tree.FlattenVisible()))
+	}
+
+	tree.FoldToDepth(0)
+	if len(tree.FlattenVisible()) != 3 {
+		t.Errorf("expected all 3 nodes when fully expanded, got %d", len(tree.FlattenVisible()))
+	}
+}
+
+func TestClone(t *testing.T) {
+	tree := NewTree()
+	tree.Root.Children = nil
+
+	parent := NewNode("parent")
+	child := NewNode("child")
+	tree.Root.AddChild(parent)
+	parent.AddChild(child)
+	parent.Expanded = false
+	parent.Comment = "note"
+
+	clone := tree.Clone()
+	clonedParent := clone.Root.Children[0]
+
+	if clonedParent == parent {
+		t.Fatal("expected a new node")
+	}
+	if clonedParent.ID != parent.ID || clonedParent.Text != "parent" || clonedParent.Comment != "note" || clonedParent.Expanded {
+		t.Error("expected cloned node to keep ID, text, comment and expanded state")
+	}
+	if clonedParent.Children[0].Parent != clonedParent {
+		t.Error("expected parent references within the clone")
+	}
+
+	clonedParent.Children[0].Text = "changed"
+	if child.Text != "child" {
+		t.Error("modifying the clone should not affect the original")
+	}
+}
+
+func TestFind(t *testing.T) {
+	tree := NewTree()
+	tree.Root.Children = nil
+
+	parent := NewNode("parent")
+	child := NewNode("child")
+	tree.Root.AddChild(parent)
+	parent.AddChild(child)
+
+	if tree.Find(child.ID) != child {
+		t.Error("expected to find nested child")
+	}
+	if tree.Find("missing") != nil {
+		t.Error("expected nil for unknown ID")
+	}
+}
+
+// outline returns the texts of all nodes, indented by depth
+func outline(tree *Tree) string {
+	var sb strings.Builder
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, c := range n.Children {
+			sb.WriteString(strings.Repeat("  ", c.Depth()-1) + c.Text + "\n")
+			walk(c)
+		}
+	}
+	walk(tree.Root)
+	return sb.String()
+}
+
+// buildTree creates a tree of a, b (with children b1, b2) and c
+func buildTree() (*Tree, map[string]*Node) {
+	tree := NewTree()
+	tree.Root.Children = nil
+	nodes := make(map[string]*Node)
+	for _, text := range []string{"a", "b", "c", "b1", "b2"} {
+		nodes[text] = NewNode(text)
+	}
+	tree.Root.AddChild(nodes["a"])
+	tree.Root.AddChild(nodes["b"])
+	tree.Root.AddChild(nodes["c"])
+	nodes["b"].AddChild(nodes["b1"])
+	nodes["b"].AddChild(nodes["b2"])
+	return tree, nodes
+}
+
+func TestMoveUp(t *testing.T) {
+	tree, nodes := buildTree()
+
+	if !tree.MoveUp(nodes["c"]) {
+		t.Fatal("expected MoveUp to return true")
+	}
+	expected := "a\nc\nb\n  b1\n  b2\n"
+	if output := outline(tree); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestMoveUpFirstChildToPreviousParent(t *testing.T) {
+	tree, nodes := buildTree()
+	nodes["a"].Expanded = false
+
+	tree.MoveUp(nodes["b1"])
+
+	expected := "a\n  b1\nb\n  b2\nc\n"
+	if output := outline(tree); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+	if !nodes["a"].Expanded {
+		t.Error("expected new parent to be expanded")
+	}
+}
+
+func TestMoveUpFirstChildOutOfParent(t *testing.T) {
+	tree, nodes := buildTree()
+	tree.MoveUp(nodes["b"])
+
+	tree.MoveUp(nodes["b1"])
+
+	expected := "b1\nb\n  b2\na\nc\n"
+	if output := outline(tree); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestMoveUpTop(t *testing.T) {
+	tree, nodes := buildTree()
+
+	if tree.MoveUp(nodes["a"]) {
+		t.Error("expected MoveUp of the first top-level node to return false")
+	}
+}
+
+func TestMoveDown(t *testing.T) {
+	tree, nodes := buildTree()
+
+	if !tree.MoveDown(nodes["a"]) {
+		t.Fatal("expected MoveDown to return true")
+	}
+	expected := "b\n  b1\n  b2\na\nc\n"
+	if output := outline(tree); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestMoveDownLastChildToNextParent(t *testing.T) {
+	tree, nodes := buildTree()
+	nodes["c"].AddChild(NewNode("c1"))
+
+	tree.MoveDown(nodes["b2"])
+
+	expected := "a\nb\n  b1\nc\n  b2\n  c1\n"
+	if output := outline(tree); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestMoveDownLastChildOutOfParent(t *testing.T) {
+	tree, nodes := buildTree()
+	tree.MoveDown(nodes["b"])
+
+	tree.MoveDown(nodes["b2"])
+
+	expected := "a\nc\nb\n  b1\nb2\n"
+	if output := outline(tree); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestMoveDownBottom(t *testing.T) {
+	tree, nodes := buildTree()
+
+	if tree.MoveDown(nodes["c"]) {
+		t.Error("expected MoveDown of the last top-level node to return false")
+	}
+}
+
+func TestInsertBefore(t *testing.T) {
+	tree, nodes := buildTree()
+
+	tree.InsertBefore(nodes["b2"], NewNode("new"))
+
+	expected := "a\nb\n  b1\n  new\n  b2\nc\n"
+	if output := outline(tree); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	parent := NewNode("parent")
+	child := NewNode("child")
+	root := NewNode("root")
+	root.AddChild(parent)
+	parent.AddChild(child)
+	parent.Expanded = false
+	child.Comment = "note"
+
+	c := parent.Copy()
+
+	if c.ID == parent.ID || c.Children[0].ID == child.ID {
+		t.Error("expected copies to get new IDs")
+	}
+	if c.Text != "parent" || c.Children[0].Text != "child" || c.Children[0].Comment != "note" || c.Expanded {
+		t.Error("expected text, comment and expanded state to be copied")
+	}
+	if c.Parent != nil {
+		t.Error("expected copy to be detached")
+	}
+	if c.Children[0].Parent != c {
+		t.Error("expected parent references within the copy")
+	}
+}
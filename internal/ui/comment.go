@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// commentHeight is the number of lines of the comment editor
+const commentHeight = 3
+
+// startComment opens the comment editor for the current node
+func (m *Model) startComment() tea.Cmd {
+	m.saveCurrentEdit()
+	node := m.currentNode()
+	if node == nil {
+		return nil
+	}
+	m.textInput.Blur()
+	m.commenting = true
+	m.commentNodeID = node.ID
+	m.commentInput.SetWidth(max(m.width-2, 10))
+	m.commentInput.SetValue(node.Comment)
+	return m.commentInput.Focus()
+}
+
+// endComment closes the comment editor
+func (m *Model) endComment() {
+	m.commenting = false
+	m.commentInput.Blur()
+	if m.mode == ModeEdit {
+		m.textInput.Focus()
+	}
+}
+
+// handleCommentKey handles keys typed into the comment editor.
+// Long comments wrap; Enter saves and Esc cancels.
+func (m Model) handleCommentKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.endComment()
+		return m, nil
+	case tea.KeyEnter:
+		m.endComment()
+		if node := m.tree.Find(m.commentNodeID); node != nil {
+			m.setComment(node, m.commentInput.Value())
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.commentInput, cmd = m.commentInput.Update(msg)
+	return m, cmd
+}
+
+// setComment sets the comment of a node. Comments are kept on one line so
+// that they fit beside the node.
+func (m *Model) setComment(node *tree.Node, comment string) {
+	comment = strings.TrimSpace(lineBreaks.Replace(comment))
+	if comment == node.Comment {
+		return
+	}
+	m.record(m.snapshot(), "comment")
+	node.Comment = comment
+	if comment == "" {
+		m.message = "Comment removed"
+	} else {
+		m.message = "Comment saved"
+	}
+}
+
+// lineBreaks turns the line breaks of a comment into spaces
+var lineBreaks = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// newCommentInput returns the text area used to edit comments
+func newCommentInput() textarea.Model {
+	ta := textarea.New()
+	ta.Prompt = "# "
+	ta.ShowLineNumbers = false
+	ta.Placeholder = "Comment"
+	ta.SetHeight(commentHeight)
+	return ta
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/radish-miyazaki/ttree/internal/document"
+)
+
+func TestEditComment(t *testing.T) {
+	tr, _ := document.Parse("src\n  main.go\n")
+	m := New(WithTree(tr))
+	m = press(m, runes("j#")...)
+	if !m.commenting {
+		t.Fatal("expected the comment editor to open")
+	}
+
+	m = press(m, runes("entry point")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.commenting {
+		t.Error("expected Enter to close the comment editor")
+	}
+	if comment := m.currentNode().Comment; comment != "entry point" {
+		t.Errorf("expected comment 'entry point', got %q", comment)
+	}
+	if !m.dirty || m.message != "Comment saved" {
+		t.Errorf("expected a saved, unsaved-to-disk comment, got dirty=%v message=%q", m.dirty, m.message)
+	}
+	if !strings.Contains(m.buildPreviewView(), "main.go  # entry point") {
+		t.Errorf("expected the comment in the preview, got:\n%s", m.buildPreviewView())
+	}
+	if !strings.Contains(m.buildEditorView(40), "# entry point") {
+		t.Errorf("expected the comment in the editor, got:\n%s", m.buildEditorView(40))
+	}
+
+	m = press(m, runes("u")...)
+	if comment := m.currentNode().Comment; comment != "" {
+		t.Errorf("expected undo to remove the comment, got %q", comment)
+	}
+}
+
+func TestEditCommentCancel(t *testing.T) {
+	tr, _ := document.Parse("main.go  # entry point\n")
+	m := New(WithTree(tr))
+	m = press(m, runes("#")...)
+	if m.commentInput.Value() != "entry point" {
+		t.Errorf("expected the editor to start with the comment, got %q", m.commentInput.Value())
+	}
+	m = press(m, runes(" changed")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if comment := m.currentNode().Comment; comment != "entry point" {
+		t.Errorf("expected Esc to keep the comment, got %q", comment)
+	}
+	if m.dirty {
+		t.Error("expected no changes")
+	}
+}
+
+func TestSetCommentFoldsLineBreaks(t *testing.T) {
+	tr, _ := document.Parse("main.go  # entry point\n")
+	m := New(WithTree(tr))
+	m.setComment(m.currentNode(), "  two\nlines\r\nkeep  spacing  ")
+	if comment := m.currentNode().Comment; comment != "two lines keep  spacing" {
+		t.Errorf("expected 'two lines keep  spacing', got %q", comment)
+	}
+	m.setComment(m.currentNode(), "")
+	if m.currentNode().Comment != "" || m.message != "Comment removed" {
+		t.Errorf("expected the comment to be removed, got %q", m.message)
+	}
+}
+
+func TestCommentEditorShrinksPanes(t *testing.T) {
+	m := New()
+	m.setMode(ModeNormal)
+	height := m.paneHeight()
+	m = press(m, runes("#")...)
+	if m.paneHeight() != height-(commentHeight-1) {
+		t.Errorf("expected panes to make room for the comment editor, got %d", m.paneHeight())
+	}
+	if lines := strings.Count(m.View(), "\n") + 1; lines != m.height {
+		t.Errorf("expected the view to fill %d lines, got %d", m.height, lines)
+	}
+}
+
+func TestCommentStaysOnItsNode(t *testing.T) {
+	m := scrollModel()
+	m = press(m, runes("#")...)
+	wheel := tea.MouseMsg{X: 5, Y: 5, Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress}
+	newModel, _ := m.Update(wheel)
+	m = newModel.(Model)
+	if m.cursor != 0 || m.editorOffset != 0 {
+		t.Errorf("expected the editor not to scroll while editing a comment, got cursor %d, offset %d", m.cursor, m.editorOffset)
+	}
+
+	// The comment goes to the node it was opened on, wherever the cursor is
+	m.cursor = 3
+	m = press(m, runes("first")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if comment := m.tree.Root.Children[0].Comment; comment != "first" {
+		t.Errorf("expected the comment on node0, got %q", comment)
+	}
+	if comment := m.tree.Root.Children[3].Comment; comment != "" {
+		t.Errorf("expected no comment on node3, got %q", comment)
+	}
+}
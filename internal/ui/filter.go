@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/radish-miyazaki/ttree/internal/search"
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// startFilter opens the filter prompt with the current filter
+func (m *Model) startFilter() tea.Cmd {
+	m.saveCurrentEdit()
+	m.textInput.Blur()
+	m.filtering = true
+	m.filterOrigin = m.currentNode()
+	m.filterInput.CursorEnd()
+	return m.filterInput.Focus()
+}
+
+// endFilter closes the filter prompt
+func (m *Model) endFilter() {
+	m.filtering = false
+	m.filterInput.Blur()
+	if m.mode == ModeEdit {
+		m.textInput.Focus()
+	}
+}
+
+// handleFilterKey handles keys typed into the filter prompt. The editor is
+// narrowed as the filter is typed.
+func (m Model) handleFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := m.keys.forMode(m.mode)
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.endFilter()
+		m.clearFilter()
+		return m, nil
+	case msg.Type == tea.KeyEnter:
+		m.endFilter()
+		switch {
+		case m.filter == nil:
+			m.clearFilter()
+		case len(m.nodes) == 0:
+			query := m.filterInput.Value()
+			m.clearFilter()
+			m.message = "Pattern not found: " + query
+		default:
+			m.message = fmt.Sprintf("Showing %d matches", len(m.filter.Nodes(m.tree)))
+		}
+		return m, nil
+	case matches(msg, keys.ToggleRegex):
+		m.searchOpts.Regex = !m.searchOpts.Regex
+		m.updateFilter()
+		return m, nil
+	case matches(msg, keys.ToggleCase):
+		m.searchOpts.IgnoreCase = !m.searchOpts.IgnoreCase
+		m.updateFilter()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	query := m.filterInput.Value()
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	if m.filterInput.Value() != query {
+		m.updateFilter()
+	}
+	return m, cmd
+}
+
+// updateFilter compiles the filter query and narrows the editor to it
+func (m *Model) updateFilter() {
+	query := m.filterInput.Value()
+	if query == "" {
+		m.setFilter(nil)
+		return
+	}
+	matcher, err := search.Compile(query, m.searchOpts)
+	if err != nil {
+		m.message = "Invalid pattern: " + err.Error()
+		m.setFilter(nil)
+		return
+	}
+	m.setFilter(matcher)
+}
+
+// clearFilter shows the whole tree again
+func (m *Model) clearFilter() {
+	m.filterInput.SetValue("")
+	m.setFilter(nil)
+}
+
+// setFilter narrows the editor to the nodes matching f and their
+// ancestors, or shows the whole tree if f is nil. The cursor stays on the
+// same node where possible.
+func (m *Model) setFilter(f *search.Matcher) {
+	m.saveCurrentEdit()
+	node := m.currentNode()
+	if node == nil {
+		node = m.filterOrigin
+	}
+	m.filter = f
+	m.filterPinned = nil
+	if f == nil && node != nil {
+		m.tree.Reveal(node)
+	}
+	m.refreshNodes()
+
+	m.cursor = indexOf(m.nodes, node)
+	if m.cursor < 0 && f != nil {
+		// The node is filtered out, so move to the first match
+		for i, n := range m.nodes {
+			if f.Match(n.Text) {
+				m.cursor = i
+				break
+			}
+		}
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	m.syncTextInput()
+}
+
+// indexOf returns the index of n in nodes, or -1
+func indexOf(nodes []*tree.Node, n *tree.Node) int {
+	for i, node := range nodes {
+		if node == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// filterKeep reports whether n is shown by the active filter
+func (m Model) filterKeep(n *tree.Node) bool {
+	return m.filter.Match(n.Text) || m.filterPinned[n.ID]
+}
+
+// pin keeps a node added while filtering visible even if it doesn't match
+func (m *Model) pin(n *tree.Node) {
+	if m.filter == nil {
+		return
+	}
+	if m.filterPinned == nil {
+		m.filterPinned = make(map[string]bool)
+	}
+	m.filterPinned[n.ID] = true
+}
+
+// toggleFilterOutput switches whether the preview and copy are filtered
+func (m *Model) toggleFilterOutput() {
+	m.filterOutput = !m.filterOutput
+	if m.filterOutput {
+		m.message = "Preview and copy show the filtered tree"
+	} else {
+		m.message = "Preview and copy show the whole tree"
+	}
+}
+
+// exportTree returns the tree shown in the preview and copied
+func (m Model) exportTree() *tree.Tree {
+	if m.filter != nil && m.filterOutput {
+		return m.tree.Filter(m.filterKeep)
+	}
+	return m.tree
+}
+
+// newFilterInput returns the text input used for the filter prompt
+func newFilterInput() textinput.Model {
+	fi := textinput.New()
+	fi.Prompt = "Filter: "
+	fi.CharLimit = 256
+	return fi
+}
@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// filterTree is src, holding main.go, notes.txt and view.go, followed by
+// README.md
+const filterTree = "src\n  main.go\n  notes.txt\n  view.go\nREADME.md\n"
+
+// visibleTexts returns the text of the nodes shown in the editor
+func visibleTexts(m Model) string {
+	var texts []string
+	for _, n := range m.nodes {
+		texts = append(texts, n.Text)
+	}
+	return strings.Join(texts, ",")
+}
+
+func TestFilterNarrowsEditor(t *testing.T) {
+	m := foldedModel(filterTree)
+	m = press(m, runes("&.go")...)
+	if !m.filtering {
+		t.Fatal("expected the filter prompt to be open")
+	}
+	if got := visibleTexts(m); got != "src,main.go,view.go" {
+		t.Errorf("expected src,main.go,view.go, got %s", got)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.filtering || m.filter == nil {
+		t.Error("expected Enter to keep the filter")
+	}
+	if m.message != "Showing 2 matches" {
+		t.Errorf("expected 'Showing 2 matches', got %q", m.message)
+	}
+	if !strings.Contains(m.buildTitle(), "[filter: .go]") {
+		t.Errorf("expected the title to show the filter, got %q", m.buildTitle())
+	}
+}
+
+func TestFilterClearKeepsCursor(t *testing.T) {
+	m := foldedModel(filterTree)
+	m = press(m, runes("&view")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = press(m, runes("j")...)
+	if node := m.currentNode(); node.Text != "view.go" {
+		t.Fatalf("expected cursor on view.go, got %q", node.Text)
+	}
+
+	// Clearing the query shows the whole tree again
+	m = press(m, runes("&")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace},
+		tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace})
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.filter != nil {
+		t.Error("expected the filter to be cleared")
+	}
+	if got := visibleTexts(m); got != "src,main.go,notes.txt,view.go,README.md" {
+		t.Errorf("expected the whole tree, got %s", got)
+	}
+	if node := m.currentNode(); node.Text != "view.go" {
+		t.Errorf("expected cursor to stay on view.go, got %q", node.Text)
+	}
+}
+
+func TestFilterEscClears(t *testing.T) {
+	m := foldedModel(filterTree)
+	m = press(m, runes("&READ")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.filtering || m.filter != nil {
+		t.Error("expected Esc to clear the filter")
+	}
+	if node := m.currentNode(); node.Text != "README.md" {
+		t.Errorf("expected cursor to stay on README.md, got %q", node.Text)
+	}
+}
+
+func TestFilterNotFound(t *testing.T) {
+	m := foldedModel(filterTree)
+	m = press(m, runes("&nothing")...)
+	if len(m.nodes) != 0 {
+		t.Errorf("expected no nodes while typing, got %d", len(m.nodes))
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.filter != nil {
+		t.Error("expected a filter without matches to be dropped")
+	}
+	if m.message != "Pattern not found: nothing" {
+		t.Errorf("unexpected message %q", m.message)
+	}
+	if node := m.currentNode(); node.Text != "src" {
+		t.Errorf("expected cursor back on src, got %q", node.Text)
+	}
+}
+
+func TestFilterKeepsNewNodes(t *testing.T) {
+	m := foldedModel(filterTree)
+	m = press(m, runes("&main")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = press(m, runes("o")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if got := visibleTexts(m); got != "src,main.go," {
+		t.Errorf("expected the new node to stay visible, got %q", got)
+	}
+}
+
+func TestFilterOutput(t *testing.T) {
+	m := foldedModel(filterTree)
+	m = press(m, runes("&main")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if strings.Contains(m.buildPreviewView(), "main.go") {
+		t.Error("expected the preview to show the whole (folded) tree by default")
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}, Alt: true})
+	expected := "└── src\n    └── main.go\n"
+	if preview := m.buildPreviewView(); preview != expected {
+		t.Errorf("expected filtered preview %q, got %q", expected, preview)
+	}
+}
+
+func TestFilterClearedWhenNoMatchesLeft(t *testing.T) {
+	m := foldedModel(filterTree)
+	m = press(m, runes("&main")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = press(m, runes("j")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlD})
+	if m.filter != nil || m.filterInput.Value() != "" {
+		t.Error("expected the filter to be cleared when nothing matches")
+	}
+	if m.message != "No matches left, filter cleared" {
+		t.Errorf("unexpected message %q", m.message)
+	}
+	if node := m.currentNode(); node == nil || node.Text != "notes.txt" {
+		t.Fatalf("expected cursor on notes.txt, got %v", node)
+	}
+
+	// The other keys work on the whole tree again
+	m = press(m, runes("o")...)
+	if got := visibleTexts(m); got != "src,notes.txt,,view.go,README.md" {
+		t.Errorf("expected a node opened after notes.txt, got %s", got)
+	}
+}
+
+func TestFilterHidesFoldMarkers(t *testing.T) {
+	m := foldedModel(filterTree)
+	m = press(m, runes("&main")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if view := m.buildEditorView(40); strings.Contains(view, "▸") || strings.Contains(view, "[+") {
+		t.Errorf("expected no fold markers while filtering, got:\n%s", view)
+	}
+}
+
+func TestFilterClearedWhenLastMatchCut(t *testing.T) {
+	m := foldedModel(filterTree)
+	m = press(m, runes("&READ")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = press(m, runes("x")...)
+	if m.filter != nil || len(m.nodes) == 0 {
+		t.Fatalf("expected the whole tree after cutting the last match, got %q", visibleTexts(m))
+	}
+	m = press(m, runes("p")...)
+	if got := visibleTexts(m); got != "src,README.md" {
+		t.Errorf("expected the cut node to be pasted back, got %s", got)
+	}
+}
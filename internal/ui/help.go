@@ -0,0 +1,243 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// helpItem is an entry of the help line. It shows the main key of each of
+// its actions, such as "j/k:move".
+type helpItem struct {
+	label   string
+	actions []string
+}
+
+// shortHelp lists the help line entries of each mode, most useful first.
+// Entries that don't fit the terminal are left out.
+var shortHelp = map[Mode][]helpItem{
+	ModeNormal: {
+		{"move", []string{"down", "up"}},
+		{"fold", []string{"collapse", "expand"}},
+		{"edit", []string{"insert", "append"}},
+		{"new", []string{"enter", "open_above"}},
+		{"indent", []string{"indent", "unindent"}},
+		{"cut/yank/paste", []string{"cut", "yank", "paste"}},
+		{"undo/redo", []string{"undo", "redo"}},
+		{"save", []string{"save"}},
+		{"quit", []string{"quit"}},
+		{"copy", []string{"copy"}},
+		{"search", []string{"search"}},
+		{"filter", []string{"filter"}},
+		{"comment", []string{"edit_comment"}},
+	},
+	ModeEdit: {
+		{"move", []string{"down", "up"}},
+		{"fold", []string{"collapse", "expand"}},
+		{"indent", []string{"indent", "unindent"}},
+		{"new", []string{"enter"}},
+		{"normal", []string{"exit_edit"}},
+		{"delete", []string{"delete"}},
+		{"undo/redo", []string{"undo", "redo"}},
+		{"save", []string{"save"}},
+		{"copy", []string{"copy"}},
+		{"search", []string{"search"}},
+		{"filter", []string{"filter"}},
+		{"comment", []string{"edit_comment"}},
+	},
+}
+
+// helpSeparator separates the entries of the help line
+const helpSeparator = " │ "
+
+// buildHelpLine returns the entries of the help line for the current mode
+// that fit the terminal width, always ending with the help key
+func (m Model) buildHelpLine() string {
+	keys := m.keys.forMode(m.mode)
+	last := (helpItem{"help", []string{"help"}}).format(keys, m.mode)
+
+	var items []string
+	for _, item := range shortHelp[m.mode] {
+		s := item.format(keys, m.mode)
+		if s == "" {
+			continue
+		}
+		line := " " + strings.Join(append(items, s, last), helpSeparator) + " "
+		if ansi.StringWidth(line) > m.width {
+			break
+		}
+		items = append(items, s)
+	}
+	if last != "" {
+		items = append(items, last)
+	}
+	return helpStyle.Render(truncate(" "+strings.Join(items, helpSeparator)+" ", m.width))
+}
+
+// format returns the entry as shown in the help line, or "" if none of
+// its actions are bound
+func (h helpItem) format(keys Bindings, mode Mode) string {
+	var shown []string
+	for _, name := range h.actions {
+		a, _ := lookupAction(name)
+		if key := mainKey(*a.keys(&keys), mode); key != "" {
+			shown = append(shown, key)
+		}
+	}
+	if len(shown) == 0 {
+		return ""
+	}
+	return strings.Join(shown, "/") + ":" + h.label
+}
+
+// mainKey returns the key shown for an action in the help line: in normal
+// mode the first typed key such as "j", otherwise the first key
+func mainKey(keys []string, mode Mode) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	if mode == ModeNormal {
+		for _, k := range keys {
+			if len([]rune(k)) == 1 || isSequence(k) {
+				return formatKey(k)
+			}
+		}
+	}
+	return formatKey(keys[0])
+}
+
+// keyLabels holds the short names shown for special keys
+var keyLabels = map[string]string{
+	"up":        "↑",
+	"down":      "↓",
+	"left":      "←",
+	"right":     "→",
+	"pgup":      "PgUp",
+	"pgdown":    "PgDn",
+	"backspace": "BS",
+	"delete":    "Del",
+	"insert":    "Ins",
+	" ":         "Space",
+}
+
+// formatKey returns the short name of a key, such as "C-s" for "ctrl+s"
+func formatKey(k string) string {
+	for _, mod := range [][2]string{{"ctrl+", "C-"}, {"alt+", "M-"}, {"shift+", "S-"}} {
+		if rest, ok := strings.CutPrefix(k, mod[0]); ok && rest != "" {
+			return mod[1] + formatKey(rest)
+		}
+	}
+	if label, ok := keyLabels[k]; ok {
+		return label
+	}
+	if keyNames[k] {
+		// Key names such as "enter" or "f1"
+		r := []rune(k)
+		r[0] = unicode.ToUpper(r[0])
+		return string(r)
+	}
+	return k
+}
+
+// startHelp opens the help overlay at the bindings of the current mode
+func (m *Model) startHelp() {
+	m.showingHelp = true
+	m.helpOffset = 0
+	if m.mode == ModeEdit {
+		lines := m.helpLines()
+		for i, line := range lines {
+			if strings.Contains(line, "Edit mode") {
+				m.helpOffset = clampOffset(i, len(lines), m.helpHeight())
+				break
+			}
+		}
+	}
+}
+
+// handleHelpKey scrolls the help overlay; Esc, q and the help keys close it
+func (m Model) handleHelpKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := m.keys.forMode(m.mode)
+	delta := 0
+	switch {
+	case msg.Type == tea.KeyEsc || msg.String() == "q" || matches(msg, keys.Help):
+		m.showingHelp = false
+		return m, nil
+	case msg.String() == "j" || matches(msg, keys.Down):
+		delta = 1
+	case msg.String() == "k" || matches(msg, keys.Up):
+		delta = -1
+	case matches(msg, keys.PageDown):
+		delta = m.helpHeight()
+	case matches(msg, keys.PageUp):
+		delta = -m.helpHeight()
+	case matches(msg, keys.Top):
+		delta = -m.helpOffset
+	case matches(msg, keys.Bottom):
+		delta = len(m.helpLines())
+	}
+	m.helpOffset = clampOffset(m.helpOffset+delta, len(m.helpLines()), m.helpHeight())
+	return m, nil
+}
+
+// helpHeight returns the number of lines visible in the help overlay
+func (m Model) helpHeight() int {
+	// Title, the border and the footer
+	return max(m.height-4, 1)
+}
+
+// helpLines returns the contents of the help overlay: a table of the
+// bound actions of each mode, generated from the current key map
+func (m Model) helpLines() []string {
+	var lines []string
+	for _, mode := range []Mode{ModeNormal, ModeEdit} {
+		keys := m.keys.forMode(mode)
+		var rows [][2]string
+		width := 0
+		for _, a := range actions {
+			bound := *a.keys(&keys)
+			if len(bound) == 0 {
+				continue
+			}
+			shown := make([]string, len(bound))
+			for i, k := range bound {
+				shown[i] = formatKey(k)
+			}
+			rows = append(rows, [2]string{strings.Join(shown, " "), a.desc})
+			width = max(width, ansi.StringWidth(rows[len(rows)-1][0]))
+		}
+
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		title := "Normal mode"
+		if mode == ModeEdit {
+			title = "Edit mode"
+		}
+		lines = append(lines, helpTitleStyle.Render(title))
+		for _, row := range rows {
+			pad := strings.Repeat(" ", width-ansi.StringWidth(row[0]))
+			lines = append(lines, helpKeyStyle.Render(row[0])+pad+"  "+row[1])
+		}
+	}
+	return lines
+}
+
+// buildHelpView renders the help overlay in place of the panes
+func (m Model) buildHelpView() string {
+	content := truncate(strings.Join(m.helpLines(), "\n"), m.width-4)
+	box := editorStyle.Width(m.width - 2).Height(m.helpHeight()).
+		Render(window(content, m.helpOffset, m.helpHeight()))
+	keys := m.keys.forMode(m.mode)
+	footer := []string{"Esc/q:close"}
+	if s := (helpItem{"scroll", []string{"down", "up", "page_down", "page_up"}}).format(keys, m.mode); s != "" {
+		footer = append([]string{s}, footer...)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(" ttree - Key bindings"),
+		box,
+		helpStyle.Render(truncate(" "+strings.Join(footer, helpSeparator)+" ", m.width)),
+	)
+}
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func sized(m Model, width, height int) Model {
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	return newModel.(Model)
+}
+
+func TestFormatKey(t *testing.T) {
+	tests := map[string]string{
+		"ctrl+s":    "C-s",
+		"alt+up":    "M-↑",
+		"shift+tab": "S-Tab",
+		"f1":        "F1",
+		"pgdown":    "PgDn",
+		"dd":        "dd",
+		"G":         "G",
+		"alt+-":     "M--",
+		"ctrl+?":    "C-?",
+	}
+	for key, expected := range tests {
+		if got := formatKey(key); got != expected {
+			t.Errorf("formatKey(%q) = %q, expected %q", key, got, expected)
+		}
+	}
+}
+
+func TestHelpLineUsesKeyMap(t *testing.T) {
+	m := sized(New(), 200, 24)
+	m.setMode(ModeNormal)
+
+	line := m.buildHelpLine()
+	for _, s := range []string{"j/k:move", "dd/yy/p:cut/yank/paste", "u/C-r:undo/redo", "C-s:save", "F1:help"} {
+		if !strings.Contains(line, s) {
+			t.Errorf("expected %q in the help line %q", s, line)
+		}
+	}
+
+	keys := DefaultKeyMap()
+	if err := keys.Apply(map[string][]string{"save": {"ctrl+w"}, "copy": {}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	m = sized(New(WithKeyMap(keys)), 200, 24)
+	m.setMode(ModeNormal)
+	line = m.buildHelpLine()
+	if !strings.Contains(line, "C-w:save") {
+		t.Errorf("expected the rebound save key in %q", line)
+	}
+	if strings.Contains(line, ":copy") {
+		t.Errorf("expected unbound copy to be left out of %q", line)
+	}
+
+	m.setMode(ModeEdit)
+	if line := m.buildHelpLine(); !strings.Contains(line, "Esc:normal") || !strings.Contains(line, "↓/↑:move") {
+		t.Errorf("expected edit mode keys in %q", line)
+	}
+}
+
+func TestHelpLineFitsWidth(t *testing.T) {
+	for _, width := range []int{30, 60, 100} {
+		m := sized(New(), width, 24)
+		m.setMode(ModeNormal)
+
+		line := m.buildHelpLine()
+		if w := lipgloss.Width(line); w > width {
+			t.Errorf("width %d: help line is %d cells wide: %q", width, w, line)
+		}
+		if !strings.HasSuffix(strings.TrimSpace(line), "F1:help") {
+			t.Errorf("width %d: expected the help key to stay visible, got %q", width, line)
+		}
+	}
+
+	narrow := sized(New(), 40, 24).buildHelpLine()
+	wide := sized(New(), 120, 24).buildHelpLine()
+	if strings.Count(narrow, helpSeparator) >= strings.Count(wide, helpSeparator) {
+		t.Errorf("expected fewer entries when narrow: %q vs %q", narrow, wide)
+	}
+}
+
+func TestHelpOverlay(t *testing.T) {
+	keys := DefaultKeyMap()
+	if err := keys.Apply(map[string][]string{"yank": {"Y"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	m := sized(New(WithKeyMap(keys)), 80, 100)
+	m.setMode(ModeNormal)
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyF1})
+	if !m.showingHelp {
+		t.Fatal("expected F1 to open the help")
+	}
+	view := m.View()
+	for _, s := range []string{"Normal mode", "Edit mode", "Yank node with subtree", "Return to normal mode"} {
+		if !strings.Contains(view, s) {
+			t.Errorf("expected %q in the help overlay", s)
+		}
+	}
+	if !strings.Contains(view, "│ Y  ") || strings.Contains(view, "│ yy ") {
+		t.Error("expected the user's yank key in place of the default")
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if w := lipgloss.Width(line); w > m.width {
+			t.Errorf("help line is %d cells wide: %q", w, line)
+		}
+	}
+
+	// Keys are swallowed by the overlay
+	m = press(m, runes("dd")...)
+	if !m.showingHelp || len(m.tree.Root.Children) != 1 {
+		t.Error("expected keys not to edit the tree while the help is open")
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.showingHelp {
+		t.Error("expected Esc to close the help")
+	}
+}
+
+func TestHelpOverlayScrolls(t *testing.T) {
+	m := sized(New(), 80, 12)
+	m.setMode(ModeEdit)
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyF1})
+	if m.helpOffset == 0 || !strings.Contains(m.View(), "Edit mode") {
+		t.Errorf("expected the help to open at the edit mode keys, offset %d", m.helpOffset)
+	}
+	if lines := strings.Split(m.View(), "\n"); len(lines) != m.height {
+		t.Errorf("expected the overlay to fill %d lines, got %d", m.height, len(lines))
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlHome})
+	if m.helpOffset != 0 {
+		t.Errorf("expected Ctrl+Home to scroll to the top, offset %d", m.helpOffset)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyDown})
+	if m.helpOffset != 2 {
+		t.Errorf("expected Down to scroll, offset %d", m.helpOffset)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyF1})
+	if m.showingHelp || m.mode != ModeEdit {
+		t.Error("expected F1 to close the help and stay in edit mode")
+	}
+}
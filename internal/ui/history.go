@@ -0,0 +1,60 @@
+package ui
+
+import "github.com/radish-miyazaki/ttree/internal/tree"
+
+// maxHistory is the maximum number of undo steps kept
+const maxHistory = 100
+
+// snapshot is a copy of the tree and the focused node at one point in time
+type snapshot struct {
+	tree   *tree.Tree
+	nodeID string
+	desc   string // Description of the edit made after this snapshot
+}
+
+// history holds undo and redo stacks of snapshots
+type history struct {
+	undo []snapshot
+	redo []snapshot
+	// textNodeID is the node whose text edits are being coalesced into
+	// the last undo step
+	textNodeID string
+}
+
+// push records the state before an edit and clears the redo stack
+func (h *history) push(s snapshot) {
+	h.undo = append(h.undo, s)
+	if len(h.undo) > maxHistory {
+		h.undo = h.undo[len(h.undo)-maxHistory:]
+	}
+	h.redo = nil
+	h.textNodeID = ""
+}
+
+// popUndo moves the latest undo step to the redo stack, replacing it with
+// current, and returns the state to restore
+func (h *history) popUndo(current snapshot) (snapshot, bool) {
+	if len(h.undo) == 0 {
+		return snapshot{}, false
+	}
+	s := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	current.desc = s.desc
+	h.redo = append(h.redo, current)
+	h.textNodeID = ""
+	return s, true
+}
+
+// popRedo moves the latest redo step back to the undo stack, replacing it
+// with current, and returns the state to restore
+func (h *history) popRedo(current snapshot) (snapshot, bool) {
+	if len(h.redo) == 0 {
+		return snapshot{}, false
+	}
+	s := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	current.desc = s.desc
+	h.undo = append(h.undo, current)
+	h.textNodeID = ""
+	return s, true
+}
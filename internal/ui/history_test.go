@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+func TestHistoryUndoRedo(t *testing.T) {
+	var h history
+	h.push(snapshot{tree: tree.NewTree(), desc: "first"})
+	h.push(snapshot{tree: tree.NewTree(), desc: "second"})
+
+	s, ok := h.popUndo(snapshot{nodeID: "now"})
+	if !ok || s.desc != "second" {
+		t.Fatalf("expected to undo 'second', got %q", s.desc)
+	}
+	if len(h.redo) != 1 || h.redo[0].desc != "second" || h.redo[0].nodeID != "now" {
+		t.Errorf("expected current state on redo stack, got %+v", h.redo)
+	}
+
+	s, ok = h.popRedo(snapshot{nodeID: "undone"})
+	if !ok || s.nodeID != "now" {
+		t.Fatalf("expected to redo to 'now', got %q", s.nodeID)
+	}
+	if len(h.undo) != 2 || h.undo[1].desc != "second" {
+		t.Errorf("expected redone step back on undo stack, got %+v", h.undo)
+	}
+}
+
+func TestHistoryEmpty(t *testing.T) {
+	var h history
+
+	if _, ok := h.popUndo(snapshot{}); ok {
+		t.Error("expected nothing to undo")
+	}
+	if _, ok := h.popRedo(snapshot{}); ok {
+		t.Error("expected nothing to redo")
+	}
+}
+
+func TestHistoryPushClearsRedo(t *testing.T) {
+	var h history
+	h.push(snapshot{desc: "first"})
+	h.popUndo(snapshot{})
+	h.textNodeID = "abc"
+
+	h.push(snapshot{desc: "second"})
+
+	if len(h.redo) != 0 {
+		t.Error("expected new edit to clear redo stack")
+	}
+	if h.textNodeID != "" {
+		t.Error("expected new edit to end text coalescing")
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	var h history
+	for i := 0; i < maxHistory+10; i++ {
+		h.push(snapshot{})
+	}
+
+	if len(h.undo) != maxHistory {
+		t.Errorf("expected %d undo steps, got %d", maxHistory, len(h.undo))
+	}
+}
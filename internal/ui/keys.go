@@ -1,45 +1,355 @@
 package ui
 
-import "github.com/charmbracelet/bubbletea"
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
-// KeyMap defines all key bindings
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Bindings maps actions to keys for a single mode. In normal mode a key
+// may be a sequence of characters such as "dd".
+type Bindings struct {
+	Up           []string
+	Down         []string
+	Top          []string
+	Bottom       []string
+	PageUp       []string
+	PageDown     []string
+	Collapse     []string // At the start of the text in edit mode
+	Expand       []string // At the end of the text in edit mode
+	CollapseAll  []string
+	ExpandAll    []string
+	FoldLevel    []string // The Nth key shows N levels
+	ToggleFolded []string // Include folded content in preview and copy
+	CycleStyle   []string // Switch the preview and copy to the next style
+	Indent       []string
+	Unindent     []string
+	MoveUp       []string // Move node above its previous sibling
+	MoveDown     []string // Move node below its next sibling
+	Enter        []string // New sibling below
+	OpenAbove    []string // New sibling above
+	Insert       []string // Edit at the start of the text
+	Append       []string // Edit at the end of the text
+	ExitEdit     []string
+	EditComment  []string // Edit the comment shown beside the node
+	Delete       []string
+	Yank         []string // Copy node and subtree to the register
+	Cut          []string // Move node and subtree to the register
+	Paste        []string // Paste register as next sibling
+	PasteAbove   []string // Paste register as previous sibling
+	PasteChild   []string // Paste register as first child
+	Search       []string
+	SearchNext   []string
+	SearchPrev   []string
+	ToggleRegex  []string // While searching
+	ToggleCase   []string // While searching, toggle case-insensitive matching
+	Filter       []string // Show only matching nodes and their ancestors
+	FilterOutput []string // Apply the filter to the preview and copy
+	Undo         []string
+	Redo         []string
+	Copy         []string
+	CopyAs       []string // Copy in a format chosen from a menu
+	Save         []string
+	Quit         []string
+	Help         []string
+}
+
+// KeyMap defines all key bindings, per mode
 type KeyMap struct {
-	Up       []string
-	Down     []string
-	Left     []string
-	Right    []string
-	Indent   []string
-	Unindent []string
-	Enter    []string
-	Delete   []string
-	Copy     []string
-	Quit     []string
-	Help     []string
+	Normal Bindings
+	Edit   Bindings
 }
 
 // DefaultKeyMap returns the default key bindings
 func DefaultKeyMap() KeyMap {
 	return KeyMap{
-		Up:       []string{"up", "k"},
-		Down:     []string{"down", "j"},
-		Left:     []string{"left"},
-		Right:    []string{"right"},
-		Indent:   []string{"tab"},
-		Unindent: []string{"shift+tab"},
-		Enter:    []string{"enter"},
-		Delete:   []string{"ctrl+d", "ctrl+backspace"},
-		Copy:     []string{"ctrl+c"},
-		Quit:     []string{"ctrl+q", "esc"},
-		Help:     []string{"ctrl+?", "f1"},
+		Normal: Bindings{
+			Up:           []string{"up", "k"},
+			Down:         []string{"down", "j"},
+			Top:          []string{"home", "gg"},
+			Bottom:       []string{"end", "G"},
+			PageUp:       []string{"pgup", "ctrl+b"},
+			PageDown:     []string{"pgdown", "ctrl+f"},
+			Collapse:     []string{"left", "h"},
+			Expand:       []string{"right", "l"},
+			CollapseAll:  []string{"alt+-", "zM"},
+			ExpandAll:    []string{"alt+=", "zR"},
+			FoldLevel:    []string{"alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9"},
+			ToggleFolded: []string{"alt+f"},
+			CycleStyle:   []string{"alt+s"},
+			Indent:       []string{"tab", ">>"},
+			Unindent:     []string{"shift+tab", "<<"},
+			MoveUp:       []string{"alt+up", "K"},
+			MoveDown:     []string{"alt+down", "J"},
+			Enter:        []string{"o"},
+			OpenAbove:    []string{"O"},
+			Insert:       []string{"i", "I"},
+			Append:       []string{"a", "A", "enter"},
+			EditComment:  []string{"#"},
+			Delete:       []string{"ctrl+d"},
+			Yank:         []string{"yy"},
+			Cut:          []string{"dd", "x"},
+			Paste:        []string{"p"},
+			PasteAbove:   []string{"P"},
+			PasteChild:   []string{">p"},
+			Search:       []string{"/"},
+			SearchNext:   []string{"n"},
+			SearchPrev:   []string{"N"},
+			ToggleRegex:  []string{"alt+r"},
+			ToggleCase:   []string{"alt+i"},
+			Filter:       []string{"&"},
+			FilterOutput: []string{"alt+p"},
+			Undo:         []string{"ctrl+z", "u"},
+			Redo:         []string{"ctrl+r", "ctrl+y"},
+			Copy:         []string{"ctrl+c"},
+			CopyAs:       []string{"alt+e"},
+			Save:         []string{"ctrl+s"},
+			Quit:         []string{"ctrl+q", "q"},
+			Help:         []string{"f1", "ctrl+?"},
+		},
+		Edit: Bindings{
+			Up:           []string{"up"},
+			Down:         []string{"down"},
+			Top:          []string{"ctrl+home"},
+			Bottom:       []string{"ctrl+end"},
+			PageUp:       []string{"pgup"},
+			PageDown:     []string{"pgdown"},
+			Collapse:     []string{"left"},
+			Expand:       []string{"right"},
+			CollapseAll:  []string{"alt+-"},
+			ExpandAll:    []string{"alt+="},
+			FoldLevel:    []string{"alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9"},
+			ToggleFolded: []string{"alt+z"},
+			CycleStyle:   []string{"alt+s"},
+			Indent:       []string{"tab"},
+			Unindent:     []string{"shift+tab"},
+			MoveUp:       []string{"alt+up"},
+			MoveDown:     []string{"alt+down"},
+			Enter:        []string{"enter"},
+			ExitEdit:     []string{"esc"},
+			EditComment:  []string{"alt+#"},
+			Delete:       []string{"ctrl+d", "ctrl+backspace"},
+			Yank:         []string{"alt+c"},
+			Cut:          []string{"alt+x"},
+			Paste:        []string{"alt+v"},
+			Search:       []string{"ctrl+f"},
+			SearchNext:   []string{"f3"},
+			ToggleRegex:  []string{"alt+r"},
+			ToggleCase:   []string{"alt+i"},
+			Filter:       []string{"alt+/"},
+			FilterOutput: []string{"alt+p"},
+			Undo:         []string{"ctrl+z"},
+			Redo:         []string{"ctrl+r", "ctrl+y"},
+			Copy:         []string{"ctrl+c"},
+			CopyAs:       []string{"alt+e"},
+			Save:         []string{"ctrl+s"},
+			Quit:         []string{"ctrl+q"},
+			Help:         []string{"f1", "ctrl+?"},
+		},
+	}
+}
+
+// forMode returns the bindings active in the given mode
+func (k KeyMap) forMode(mode Mode) Bindings {
+	if mode == ModeNormal {
+		return k.Normal
+	}
+	return k.Edit
+}
+
+// action names a binding for config files and describes it for the help
+type action struct {
+	name string
+	desc string
+	keys func(b *Bindings) *[]string
+}
+
+// actions lists every binding in help order
+var actions = []action{
+	{"up", "Move up", func(b *Bindings) *[]string { return &b.Up }},
+	{"down", "Move down", func(b *Bindings) *[]string { return &b.Down }},
+	{"top", "Move to the first node", func(b *Bindings) *[]string { return &b.Top }},
+	{"bottom", "Move to the last node", func(b *Bindings) *[]string { return &b.Bottom }},
+	{"page_up", "Scroll up a page", func(b *Bindings) *[]string { return &b.PageUp }},
+	{"page_down", "Scroll down a page", func(b *Bindings) *[]string { return &b.PageDown }},
+	{"collapse", "Collapse node", func(b *Bindings) *[]string { return &b.Collapse }},
+	{"expand", "Expand node", func(b *Bindings) *[]string { return &b.Expand }},
+	{"collapse_all", "Collapse all nodes", func(b *Bindings) *[]string { return &b.CollapseAll }},
+	{"expand_all", "Expand all nodes", func(b *Bindings) *[]string { return &b.ExpandAll }},
+	{"fold_level", "Show N levels (Nth key)", func(b *Bindings) *[]string { return &b.FoldLevel }},
+	{"toggle_folded", "Show folded nodes in preview and copy", func(b *Bindings) *[]string { return &b.ToggleFolded }},
+	{"cycle_style", "Cycle preview and copy style", func(b *Bindings) *[]string { return &b.CycleStyle }},
+	{"indent", "Indent node", func(b *Bindings) *[]string { return &b.Indent }},
+	{"unindent", "Unindent node", func(b *Bindings) *[]string { return &b.Unindent }},
+	{"move_up", "Move node up", func(b *Bindings) *[]string { return &b.MoveUp }},
+	{"move_down", "Move node down", func(b *Bindings) *[]string { return &b.MoveDown }},
+	{"enter", "New sibling below", func(b *Bindings) *[]string { return &b.Enter }},
+	{"open_above", "New sibling above", func(b *Bindings) *[]string { return &b.OpenAbove }},
+	{"insert", "Edit at the start of the text", func(b *Bindings) *[]string { return &b.Insert }},
+	{"append", "Edit at the end of the text", func(b *Bindings) *[]string { return &b.Append }},
+	{"exit_edit", "Return to normal mode", func(b *Bindings) *[]string { return &b.ExitEdit }},
+	{"edit_comment", "Edit the node's comment", func(b *Bindings) *[]string { return &b.EditComment }},
+	{"delete", "Delete node", func(b *Bindings) *[]string { return &b.Delete }},
+	{"yank", "Yank node with subtree", func(b *Bindings) *[]string { return &b.Yank }},
+	{"cut", "Cut node with subtree", func(b *Bindings) *[]string { return &b.Cut }},
+	{"paste", "Paste below", func(b *Bindings) *[]string { return &b.Paste }},
+	{"paste_above", "Paste above", func(b *Bindings) *[]string { return &b.PasteAbove }},
+	{"paste_child", "Paste as first child", func(b *Bindings) *[]string { return &b.PasteChild }},
+	{"search", "Search", func(b *Bindings) *[]string { return &b.Search }},
+	{"search_next", "Next match", func(b *Bindings) *[]string { return &b.SearchNext }},
+	{"search_prev", "Previous match", func(b *Bindings) *[]string { return &b.SearchPrev }},
+	{"toggle_regex", "Toggle regex while searching", func(b *Bindings) *[]string { return &b.ToggleRegex }},
+	{"toggle_case", "Toggle ignore case while searching", func(b *Bindings) *[]string { return &b.ToggleCase }},
+	{"filter", "Filter to matching nodes", func(b *Bindings) *[]string { return &b.Filter }},
+	{"filter_output", "Apply the filter to preview and copy", func(b *Bindings) *[]string { return &b.FilterOutput }},
+	{"undo", "Undo", func(b *Bindings) *[]string { return &b.Undo }},
+	{"redo", "Redo", func(b *Bindings) *[]string { return &b.Redo }},
+	{"copy", "Copy tree to clipboard", func(b *Bindings) *[]string { return &b.Copy }},
+	{"copy_as", "Copy tree in a chosen format", func(b *Bindings) *[]string { return &b.CopyAs }},
+	{"save", "Save tree to file", func(b *Bindings) *[]string { return &b.Save }},
+	{"quit", "Quit", func(b *Bindings) *[]string { return &b.Quit }},
+	{"help", "Show this help", func(b *Bindings) *[]string { return &b.Help }},
+}
+
+// lookupAction returns the action with the given name
+func lookupAction(name string) (action, bool) {
+	i := slices.IndexFunc(actions, func(a action) bool { return a.name == name })
+	if i < 0 {
+		return action{}, false
 	}
+	return actions[i], true
+}
+
+// all returns the keys of every action
+func (b Bindings) all() [][]string {
+	result := make([][]string, 0, len(actions))
+	for _, a := range actions {
+		result = append(result, *a.keys(&b))
+	}
+	return result
+}
+
+// Apply replaces the keys of the actions named in normal and edit, such as
+// "up" or "paste_child", leaving the other actions at their current keys.
+// An empty list unbinds an action. Unknown actions and keys bound twice
+// are reported as errors.
+func (k *KeyMap) Apply(normal, edit map[string][]string) error {
+	if err := k.Normal.apply(normal); err != nil {
+		return fmt.Errorf("normal mode: %w", err)
+	}
+	if err := k.Edit.apply(edit); err != nil {
+		return fmt.Errorf("edit mode: %w", err)
+	}
+	return k.Validate()
+}
+
+// apply replaces the keys of the named actions
+func (b *Bindings) apply(keys map[string][]string) error {
+	for _, name := range slices.Sorted(maps.Keys(keys)) {
+		a, ok := lookupAction(name)
+		if !ok {
+			return fmt.Errorf("unknown action %q", name)
+		}
+		*a.keys(b) = slices.Clone(keys[name])
+	}
+	return nil
+}
+
+// Validate reports keys bound to more than one action, sequences that
+// can never be typed because a shorter key runs first, and edit mode keys
+// that would shadow typing
+func (k KeyMap) Validate() error {
+	if err := k.Normal.validate(ModeNormal); err != nil {
+		return fmt.Errorf("normal mode: %w", err)
+	}
+	if err := k.Edit.validate(ModeEdit); err != nil {
+		return fmt.Errorf("edit mode: %w", err)
+	}
+	return nil
+}
+
+// validate checks the bindings of a single mode
+func (b Bindings) validate(mode Mode) error {
+	bound := make(map[string]string) // key -> action
+	for _, a := range actions {
+		for _, key := range *a.keys(&b) {
+			if key == "" {
+				return fmt.Errorf("empty key for %s", a.name)
+			}
+			if mode == ModeEdit && (len([]rune(key)) == 1 || isSequence(key)) {
+				return fmt.Errorf("%q for %s would shadow typing", key, a.name)
+			}
+			if other, ok := bound[key]; ok && other != a.name {
+				return fmt.Errorf("%q is bound to both %s and %s", key, other, a.name)
+			}
+			bound[key] = a.name
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(bound)) {
+		name := bound[key]
+		if !isSequence(key) {
+			continue
+		}
+		for i := 1; i < len(key); i++ {
+			if other, ok := bound[key[:i]]; ok {
+				return fmt.Errorf("%q for %s can't be typed because %q is bound to %s", key, name, key[:i], other)
+			}
+		}
+	}
+	return nil
+}
+
+// isPrefix reports whether seq is the start of a longer key sequence
+func (b Bindings) isPrefix(seq string) bool {
+	for _, keys := range b.all() {
+		for _, k := range keys {
+			if isSequence(k) && len(k) > len(seq) && strings.HasPrefix(k, seq) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keyNames holds the names of special keys such as "end" or "f1"
+var keyNames = func() map[string]bool {
+	names := make(map[string]bool)
+	for t := tea.KeyType(-128); t < 128; t++ {
+		if s := t.String(); s != "" {
+			names[s] = true
+		}
+	}
+	return names
+}()
+
+// isSequence reports whether k is a sequence of typed characters
+// rather than the name of a single key
+func isSequence(k string) bool {
+	if len([]rune(k)) <= 1 || keyNames[k] {
+		return false
+	}
+	for _, modifier := range []string{"ctrl+", "shift+", "alt+"} {
+		if strings.HasPrefix(k, modifier) {
+			return false
+		}
+	}
+	return true
 }
 
 // matches checks if a key message matches any of the given keys
 func matches(msg tea.KeyMsg, keys []string) bool {
-	for _, k := range keys {
+	return matchIndex(msg, keys) >= 0
+}
+
+// matchIndex returns the index of the key matching msg, or -1
+func matchIndex(msg tea.KeyMsg, keys []string) int {
+	for i, k := range keys {
 		if msg.String() == k {
-			return true
+			return i
 		}
 	}
-	return false
+	return -1
 }
@@ -7,7 +7,7 @@ import (
 )
 
 func TestDefaultKeyMap(t *testing.T) {
-	km := DefaultKeyMap()
+	km := DefaultKeyMap().Normal
 
 	if len(km.Up) == 0 {
 		t.Error("expected Up keys to be defined")
@@ -30,13 +30,16 @@ func TestDefaultKeyMap(t *testing.T) {
 	if len(km.Copy) == 0 {
 		t.Error("expected Copy keys to be defined")
 	}
+	if len(km.Save) == 0 {
+		t.Error("expected Save keys to be defined")
+	}
 	if len(km.Quit) == 0 {
 		t.Error("expected Quit keys to be defined")
 	}
 }
 
 func TestMatchesUp(t *testing.T) {
-	km := DefaultKeyMap()
+	km := DefaultKeyMap().Normal
 
 	tests := []struct {
 		key      tea.KeyMsg
@@ -57,7 +60,7 @@ func TestMatchesUp(t *testing.T) {
 }
 
 func TestMatchesDown(t *testing.T) {
-	km := DefaultKeyMap()
+	km := DefaultKeyMap().Normal
 
 	tests := []struct {
 		key      tea.KeyMsg
@@ -77,7 +80,7 @@ func TestMatchesDown(t *testing.T) {
 }
 
 func TestMatchesIndent(t *testing.T) {
-	km := DefaultKeyMap()
+	km := DefaultKeyMap().Edit
 
 	msg := tea.KeyMsg{Type: tea.KeyTab}
 	if !matches(msg, km.Indent) {
@@ -86,7 +89,7 @@ func TestMatchesIndent(t *testing.T) {
 }
 
 func TestMatchesUnindent(t *testing.T) {
-	km := DefaultKeyMap()
+	km := DefaultKeyMap().Edit
 
 	msg := tea.KeyMsg{Type: tea.KeyShiftTab}
 	if !matches(msg, km.Unindent) {
@@ -95,7 +98,7 @@ func TestMatchesUnindent(t *testing.T) {
 }
 
 func TestMatchesEnter(t *testing.T) {
-	km := DefaultKeyMap()
+	km := DefaultKeyMap().Edit
 
 	msg := tea.KeyMsg{Type: tea.KeyEnter}
 	if !matches(msg, km.Enter) {
@@ -104,7 +107,7 @@ func TestMatchesEnter(t *testing.T) {
 }
 
 func TestMatchesDelete(t *testing.T) {
-	km := DefaultKeyMap()
+	km := DefaultKeyMap().Edit
 
 	msg := tea.KeyMsg{Type: tea.KeyCtrlD}
 	if !matches(msg, km.Delete) {
@@ -113,7 +116,7 @@ func TestMatchesDelete(t *testing.T) {
 }
 
 func TestMatchesCopy(t *testing.T) {
-	km := DefaultKeyMap()
+	km := DefaultKeyMap().Edit
 
 	msg := tea.KeyMsg{Type: tea.KeyCtrlC}
 	if !matches(msg, km.Copy) {
@@ -122,14 +125,15 @@ func TestMatchesCopy(t *testing.T) {
 }
 
 func TestMatchesQuit(t *testing.T) {
-	km := DefaultKeyMap()
+	km := DefaultKeyMap().Normal
 
 	tests := []struct {
 		key      tea.KeyMsg
 		expected bool
 	}{
 		{tea.KeyMsg{Type: tea.KeyCtrlQ}, true},
-		{tea.KeyMsg{Type: tea.KeyEscape}, true},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}}, true},
+		{tea.KeyMsg{Type: tea.KeyEscape}, false},
 		{tea.KeyMsg{Type: tea.KeyEnter}, false},
 	}
 
@@ -147,3 +151,124 @@ func TestMatchesEmpty(t *testing.T) {
 		t.Error("should not match empty key list")
 	}
 }
+
+func TestEditKeysAreNotPrintable(t *testing.T) {
+	km := DefaultKeyMap().Edit
+
+	for _, keys := range km.all() {
+		for _, k := range keys {
+			if len([]rune(k)) == 1 || isSequence(k) {
+				t.Errorf("edit mode key %q would shadow typing", k)
+			}
+		}
+	}
+}
+
+func TestMatchesExitEdit(t *testing.T) {
+	km := DefaultKeyMap()
+
+	msg := tea.KeyMsg{Type: tea.KeyEscape}
+	if !matches(msg, km.Edit.ExitEdit) {
+		t.Error("Esc should leave edit mode")
+	}
+	if matches(msg, km.Edit.Quit) {
+		t.Error("Esc should not quit in edit mode")
+	}
+}
+
+func TestIsSequence(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected bool
+	}{
+		{"dd", true},
+		{">>", true},
+		{"gg", true},
+		{"d", false},
+		{"end", false},
+		{"up", false},
+		{"f1", false},
+		{"ctrl+d", false},
+		{"ctrl+backspace", false},
+		{"alt+-", false},
+	}
+
+	for _, tt := range tests {
+		if result := isSequence(tt.key); result != tt.expected {
+			t.Errorf("isSequence(%q) = %v, expected %v", tt.key, result, tt.expected)
+		}
+	}
+}
+
+func TestIsPrefix(t *testing.T) {
+	km := DefaultKeyMap().Normal
+
+	if !km.isPrefix("d") {
+		t.Error("'d' should start 'dd'")
+	}
+	if km.isPrefix("dd") {
+		t.Error("'dd' is a complete sequence")
+	}
+	if km.isPrefix("e") {
+		t.Error("'e' should not wait for the 'end' key name")
+	}
+	if km.isPrefix("u") {
+		t.Error("'u' should not wait for the 'up' key name")
+	}
+}
+
+func TestDefaultKeyMapIsValid(t *testing.T) {
+	if err := DefaultKeyMap().Validate(); err != nil {
+		t.Errorf("default key map: %v", err)
+	}
+}
+
+func TestApplyKeys(t *testing.T) {
+	km := DefaultKeyMap()
+	err := km.Apply(
+		map[string][]string{"up": {"ctrl+p"}, "paste_child": {}},
+		map[string][]string{"save": {"ctrl+w"}},
+	)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	if len(km.Normal.Up) != 1 || km.Normal.Up[0] != "ctrl+p" {
+		t.Errorf("normal Up = %v, expected [ctrl+p]", km.Normal.Up)
+	}
+	if len(km.Normal.PasteChild) != 0 {
+		t.Errorf("normal PasteChild = %v, expected it to be unbound", km.Normal.PasteChild)
+	}
+	if len(km.Normal.Down) != 2 {
+		t.Errorf("normal Down = %v, expected the defaults", km.Normal.Down)
+	}
+	if len(km.Edit.Save) != 1 || km.Edit.Save[0] != "ctrl+w" {
+		t.Errorf("edit Save = %v, expected [ctrl+w]", km.Edit.Save)
+	}
+	if len(km.Normal.Save) != 1 || km.Normal.Save[0] != "ctrl+s" {
+		t.Errorf("normal Save = %v, expected the default", km.Normal.Save)
+	}
+}
+
+func TestApplyKeysErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		normal map[string][]string
+		edit   map[string][]string
+		want   string
+	}{
+		{"unknown action", map[string][]string{"jump": {"J"}}, nil, `normal mode: unknown action "jump"`},
+		{"conflict", map[string][]string{"up": {"j"}}, nil, `normal mode: "j" is bound to both up and down`},
+		{"prefix", map[string][]string{"paste": {"y"}}, nil, `normal mode: "yy" for yank can't be typed because "y" is bound to paste`},
+		{"printable", nil, map[string][]string{"undo": {"u"}}, `edit mode: "u" for undo would shadow typing`},
+		{"empty key", nil, map[string][]string{"undo": {""}}, `edit mode: empty key for undo`},
+	}
+
+	for _, tt := range tests {
+		km := DefaultKeyMap()
+		err := km.Apply(tt.normal, tt.edit)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: Apply error = %v, expected %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -1,9 +1,17 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/radish-miyazaki/ttree/internal/clipboard"
+	"github.com/radish-miyazaki/ttree/internal/document"
 	"github.com/radish-miyazaki/ttree/internal/render"
+	"github.com/radish-miyazaki/ttree/internal/search"
 	"github.com/radish-miyazaki/ttree/internal/tree"
 )
 
@@ -15,6 +23,8 @@ const (
 	ModeEdit
 )
 
+var errNoPath = errors.New("no file name (start ttree with a file path)")
+
 // Model represents the application state
 type Model struct {
 	tree      *tree.Tree
@@ -26,40 +36,192 @@ type Model struct {
 	width     int
 	height    int
 	keys      KeyMap
-	message   string // Status message
-	copied    bool   // Flash message for copy
+	split     int             // Editor share of the width, in percent
+	message   string          // Status message
+	copied    bool            // Flash message for copy
+	path      string          // File the tree is saved to
+	format    document.Format // Format used when saving
+	dirty     bool            // Unsaved changes
+	quitting  bool            // Waiting for save-on-quit confirmation
+	history   history
+	pending   string            // Partially typed key sequence in normal mode
+	register  *tree.Node        // Yanked or cut subtree
+	clipboard clipboard.Backend // Where copied trees are written
+
+	choosingFormat bool // Waiting for the copy-as format
+
+	printOnExit     bool   // Offer to print the tree to stdout when quitting
+	confirmingPrint bool   // Waiting for print-on-quit confirmation
+	output          string // Printed to stdout after the program exits
+
+	searching    bool // Typing a search query
+	searchInput  textinput.Model
+	searchOpts   search.Options
+	searchOrigin *tree.Node      // Node focused when the search started
+	matcher      *search.Matcher // Last search, for highlighting and n/N
+
+	filtering    bool // Typing a filter
+	filterInput  textinput.Model
+	filterOrigin *tree.Node      // Node focused when the filter prompt opened
+	filter       *search.Matcher // Active filter, nil shows the whole tree
+	filterPinned map[string]bool // Nodes added while filtering stay visible
+	filterOutput bool            // Filter the preview and copy too
+
+	editorOffset  int // First editor line shown
+	previewOffset int // First preview line shown
+
+	dragNode   *tree.Node  // Row being dragged with the mouse
+	dropTarget *dropTarget // Where the dragged row would be dropped
+
+	commenting    bool // Editing the current node's comment
+	commentInput  textarea.Model
+	commentNodeID string // Node whose comment is being edited
+
+	showingHelp bool // Help overlay in place of the panes
+	helpOffset  int  // First help line shown
+}
+
+// Option configures a Model
+type Option func(*Model)
+
+// WithTree starts the editor with an existing tree
+func WithTree(t *tree.Tree) Option {
+	return func(m *Model) {
+		m.tree = t
+	}
+}
+
+// WithPath sets the file the tree is saved to
+func WithPath(path string) Option {
+	return func(m *Model) {
+		m.path = path
+	}
+}
+
+// WithFormat sets the format the tree is saved in. ASCII trees are
+// also previewed in their own style.
+func WithFormat(format document.Format) Option {
+	return func(m *Model) {
+		m.format = format
+		if format.ASCII {
+			m.renderer.Style = format.Style
+		}
+	}
+}
+
+// WithCommentAlign sets how comments are aligned in the preview and copy
+func WithCommentAlign(align render.CommentAlign) Option {
+	return func(m *Model) {
+		m.renderer.CommentAlign = align
+	}
+}
+
+// WithCommentDelimiter makes the preview and copy align text after
+// delimiter as a comment
+func WithCommentDelimiter(delimiter string) Option {
+	return func(m *Model) {
+		m.renderer.CommentDelimiter = delimiter
+	}
+}
+
+// WithStyle sets the style of the preview and copied tree
+func WithStyle(style render.Style) Option {
+	return func(m *Model) {
+		m.renderer.Style = style
+	}
+}
+
+// WithKeyMap replaces the default key bindings
+func WithKeyMap(keys KeyMap) Option {
+	return func(m *Model) {
+		m.keys = keys
+	}
+}
+
+// WithSplit sets the percentage of the width given to the editor pane.
+// The rest goes to the preview. Use CheckSplit to reject a percentage that
+// would be clamped.
+func WithSplit(percent int) Option {
+	return func(m *Model) {
+		m.split = min(max(percent, minSplit), maxSplit)
+	}
+}
+
+// CheckSplit returns an error if WithSplit would not use percent as given
+func CheckSplit(percent int) error {
+	if percent < minSplit || percent > maxSplit {
+		return fmt.Errorf("split must be a percentage between %d and %d, got %d", minSplit, maxSplit, percent)
+	}
+	return nil
+}
+
+// WithClipboard sets where copied trees are written
+func WithClipboard(b clipboard.Backend) Option {
+	return func(m *Model) {
+		m.clipboard = b
+	}
+}
+
+// WithPrint makes quitting offer to print the tree, see Output
+func WithPrint() Option {
+	return func(m *Model) {
+		m.printOnExit = true
+	}
 }
 
 // New creates a new model
-func New() Model {
+func New(opts ...Option) Model {
 	ti := textinput.New()
 	ti.CharLimit = 256
 	ti.Width = 50
 
-	t := tree.NewTree()
-	nodes := t.FlattenVisible()
-
 	m := Model{
-		tree:      t,
-		renderer:  render.NewRenderer(),
-		cursor:    0,
-		nodes:     nodes,
-		mode:      ModeEdit,
-		textInput: ti,
-		keys:      DefaultKeyMap(),
-		width:     80,
-		height:    24,
-	}
-
-	// Start editing the first node
-	if len(nodes) > 0 {
-		m.textInput.SetValue(nodes[0].Text)
-		m.textInput.Focus()
+		tree:         tree.NewTree(),
+		renderer:     render.NewRenderer(),
+		cursor:       0,
+		mode:         ModeNormal,
+		textInput:    ti,
+		searchInput:  newSearchInput(),
+		filterInput:  newFilterInput(),
+		commentInput: newCommentInput(),
+		keys:         DefaultKeyMap(),
+		clipboard:    clipboard.System{},
+		split:        50,
+		width:        80,
+		height:       24,
+	}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	m.nodes = m.tree.FlattenVisible()
+
+	// Start editing right away if the tree is empty
+	if len(m.nodes) > 0 {
+		m.textInput.SetValue(m.nodes[0].Text)
+		if len(m.nodes) == 1 && m.nodes[0].Text == "" {
+			m.setMode(ModeEdit)
+		}
 	}
 
 	return m
 }
 
+// Output returns the tree the user chose to print when quitting, or ""
+func (m Model) Output() string {
+	return m.output
+}
+
+// setMode switches between normal and edit mode
+func (m *Model) setMode(mode Mode) {
+	m.saveCurrentEdit()
+	m.mode = mode
+	if mode == ModeEdit {
+		m.textInput.Focus()
+	} else {
+		m.textInput.Blur()
+	}
+}
+
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
 	return textinput.Blink
@@ -75,7 +237,19 @@ func (m *Model) currentNode() *tree.Node {
 
 // refreshNodes updates the flattened node list
 func (m *Model) refreshNodes() {
-	m.nodes = m.tree.FlattenVisible()
+	if m.filter != nil {
+		m.nodes = m.tree.FlattenMatching(m.filterKeep)
+		if len(m.nodes) == 0 && !m.filtering {
+			// Nothing matches any more, so show the whole tree instead
+			m.filter = nil
+			m.filterPinned = nil
+			m.filterInput.SetValue("")
+			m.message = "No matches left, filter cleared"
+		}
+	}
+	if m.filter == nil {
+		m.nodes = m.tree.FlattenVisible()
+	}
 	if m.cursor >= len(m.nodes) {
 		m.cursor = len(m.nodes) - 1
 	}
@@ -90,13 +264,87 @@ func (m *Model) syncTextInput() {
 		m.textInput.SetValue(node.Text)
 		m.textInput.CursorEnd()
 	}
+	// Further typing starts a new undo step
+	m.history.textNodeID = ""
 }
 
-// saveCurrentEdit saves the current text input to the node
+// saveCurrentEdit saves the current text input to the node. Consecutive
+// edits to the same node are coalesced into one undo step.
 func (m *Model) saveCurrentEdit() {
+	node := m.currentNode()
+	if node == nil || node.Text == m.textInput.Value() {
+		return
+	}
+	if m.history.textNodeID != node.ID {
+		m.record(m.snapshot(), "text edit")
+		m.history.textNodeID = node.ID
+	}
+	node.Text = m.textInput.Value()
+}
+
+// snapshot captures the tree and the focused node
+func (m *Model) snapshot() snapshot {
+	s := snapshot{tree: m.tree.Clone()}
 	if node := m.currentNode(); node != nil {
-		node.Text = m.textInput.Value()
+		s.nodeID = node.ID
+	}
+	return s
+}
+
+// record pushes the state from before an edit onto the undo stack
+func (m *Model) record(before snapshot, desc string) {
+	before.desc = desc
+	m.history.push(before)
+	m.dirty = true
+}
+
+// restore replaces the tree and focus with a snapshot
+func (m *Model) restore(s snapshot) {
+	m.tree = s.tree
+	m.dirty = true
+	m.refreshNodes()
+	if node := m.tree.Find(s.nodeID); node != nil {
+		m.focusNode(node.VisibleAncestor())
+		return
 	}
+	m.syncTextInput()
+}
+
+// undo reverts the last edit
+func (m *Model) undo() {
+	m.saveCurrentEdit()
+	s, ok := m.history.popUndo(m.snapshot())
+	if !ok {
+		m.message = "Nothing to undo"
+		return
+	}
+	m.restore(s)
+	m.message = "Undid " + s.desc
+}
+
+// redo reapplies the last undone edit
+func (m *Model) redo() {
+	m.saveCurrentEdit()
+	s, ok := m.history.popRedo(m.snapshot())
+	if !ok {
+		m.message = "Nothing to redo"
+		return
+	}
+	m.restore(s)
+	m.message = "Redid " + s.desc
+}
+
+// save writes the tree to its file
+func (m *Model) save() error {
+	if m.path == "" {
+		return errNoPath
+	}
+	m.saveCurrentEdit()
+	if err := document.Save(m.path, m.tree, m.format); err != nil {
+		return err
+	}
+	m.dirty = false
+	return nil
 }
 
 // moveCursor moves the cursor and handles edit sync
@@ -119,3 +367,199 @@ func (m *Model) focusNode(n *tree.Node) {
 		}
 	}
 }
+
+// moveNode applies a structural move to the current node and keeps it focused
+func (m *Model) moveNode(move func(n *tree.Node) bool, desc string) {
+	m.saveCurrentEdit()
+	node := m.currentNode()
+	if node == nil {
+		return
+	}
+	before := m.snapshot()
+	if move(node) {
+		m.record(before, desc)
+	}
+	m.refreshNodes()
+	m.focusNode(node)
+}
+
+// openNode inserts a new empty node next to the current one and starts
+// editing it
+func (m *Model) openNode(insert func(n, newNode *tree.Node)) {
+	m.saveCurrentEdit()
+	if node := m.currentNode(); node != nil {
+		newNode := tree.NewNode("")
+		m.record(m.snapshot(), "new node")
+		insert(node, newNode)
+		m.pin(newNode)
+		m.refreshNodes()
+		m.focusNode(newNode)
+	}
+	m.setMode(ModeEdit)
+}
+
+// collapseCurrent folds the current node, or moves to its parent if it
+// has nothing to fold
+func (m *Model) collapseCurrent() {
+	node := m.currentNode()
+	if node == nil {
+		return
+	}
+	m.saveCurrentEdit()
+	if m.tree.Collapse(node) {
+		m.refreshNodes()
+		m.focusNode(node)
+		return
+	}
+	if node.Parent != nil && node.Parent != m.tree.Root {
+		m.focusNode(node.Parent)
+	}
+}
+
+// expandCurrent unfolds the current node, or moves to its first child if
+// it is already expanded
+func (m *Model) expandCurrent() {
+	node := m.currentNode()
+	if node == nil {
+		return
+	}
+	m.saveCurrentEdit()
+	if m.tree.Expand(node) {
+		m.refreshNodes()
+		m.focusNode(node)
+		return
+	}
+	if len(node.Children) > 0 {
+		m.focusNode(node.Children[0])
+	}
+}
+
+// foldToDepth shows depth levels of the tree (0 = all), keeping the
+// cursor on the current node or the ancestor that now hides it
+func (m *Model) foldToDepth(depth int) {
+	node := m.currentNode()
+	m.saveCurrentEdit()
+	m.tree.FoldToDepth(depth)
+	m.refreshNodes()
+	if node != nil {
+		m.focusNode(node.VisibleAncestor())
+	}
+}
+
+// yank copies the current node and its subtree to the register
+func (m *Model) yank() {
+	m.saveCurrentEdit()
+	node := m.currentNode()
+	if node == nil {
+		return
+	}
+	m.register = node.Copy()
+	m.message = fmt.Sprintf("Yanked %d node(s)", m.register.DescendantCount()+1)
+}
+
+// cut moves the current node and its subtree to the register
+func (m *Model) cut() {
+	m.saveCurrentEdit()
+	node := m.currentNode()
+	if node == nil {
+		return
+	}
+	m.register = node.Copy()
+	m.record(m.snapshot(), "cut")
+	if node.Parent == m.tree.Root && len(m.tree.Root.Children) == 1 {
+		// The tree keeps one node, so the cut subtree becomes an empty node
+		m.tree.InsertAfter(node, tree.NewNode(""))
+	}
+	m.deleteNode(node)
+	m.message = fmt.Sprintf("Cut %d node(s)", m.register.DescendantCount()+1)
+}
+
+// deleteNode removes node and focuses the node that takes its place. If
+// the filter has no matches left, that node is shown in the whole tree.
+func (m *Model) deleteNode(node *tree.Node) {
+	filtered := m.filter != nil
+	nextFocus := m.tree.Delete(node)
+	m.refreshNodes()
+	if nextFocus == nil {
+		return
+	}
+	if filtered && m.filter == nil {
+		m.tree.Reveal(nextFocus)
+		m.refreshNodes()
+	}
+	m.focusNode(nextFocus)
+}
+
+// paste inserts a fresh copy of the register relative to the current node
+func (m *Model) paste(insert func(n, newNode *tree.Node)) {
+	m.saveCurrentEdit()
+	node := m.currentNode()
+	if node == nil {
+		return
+	}
+	if m.register == nil {
+		m.message = "Register is empty"
+		return
+	}
+	// Each paste gets new IDs so duplicated subtrees never share them
+	pasted := m.register.Copy()
+	m.record(m.snapshot(), "paste")
+	insert(node, pasted)
+	m.pin(pasted)
+	m.refreshNodes()
+	m.focusNode(pasted)
+	m.message = fmt.Sprintf("Pasted %d node(s)", pasted.DescendantCount()+1)
+}
+
+// pasteText inserts pasted lines as a subtree after the current node,
+// keeping their hierarchy. An empty current node is replaced.
+func (m *Model) pasteText(text string) {
+	m.saveCurrentEdit()
+	node := m.currentNode()
+	if node == nil {
+		return
+	}
+	t, _ := document.Parse(text)
+	pasted := slices.Clone(t.Root.Children)
+	m.record(m.snapshot(), "paste")
+	count := 0
+	after := node
+	for _, n := range pasted {
+		m.tree.InsertAfter(after, n)
+		m.pin(n)
+		after = n
+		count += n.DescendantCount() + 1
+	}
+	if node.Text == "" && node.Comment == "" && len(node.Children) == 0 {
+		m.tree.Delete(node)
+	}
+	m.refreshNodes()
+	m.focusNode(pasted[0])
+	m.message = fmt.Sprintf("Pasted %d node(s)", count)
+}
+
+// cycleStyle switches the preview and copy to the next built-in style
+func (m *Model) cycleStyle() {
+	m.renderer.Style = render.NextStyle(m.renderer.Style)
+	m.message = "Style: " + render.StyleName(m.renderer.Style)
+}
+
+// copyAs copies the tree to the clipboard in the named export format
+func (m *Model) copyAs(format string) {
+	m.saveCurrentEdit()
+	exporter, err := render.LookupExporter(format, m.renderer)
+	if err != nil {
+		m.message = "Failed to copy: " + err.Error()
+		return
+	}
+	if err := m.clipboard.Write(exporter.Export(m.exportTree())); err != nil {
+		m.message = "Failed to copy: " + err.Error()
+		return
+	}
+	m.copied = true
+	if format == "ascii" {
+		m.message = "Copied to " + m.clipboard.Name() + "!"
+	} else {
+		m.message = "Copied to " + m.clipboard.Name() + " as " + format + "!"
+	}
+}
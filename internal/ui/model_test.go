@@ -1,9 +1,16 @@
 package ui
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/radish-miyazaki/ttree/internal/document"
+	"github.com/radish-miyazaki/ttree/internal/render"
 )
 
 func TestNew(t *testing.T) {
@@ -309,3 +316,954 @@ func TestViewLoading(t *testing.T) {
 		t.Errorf("expected 'Loading...' when width is 0, got %q", view)
 	}
 }
+
+func TestNewWithTree(t *testing.T) {
+	tr, _ := document.Parse("a\n  b\n")
+	m := New(WithTree(tr), WithPath("tree.txt"))
+
+	if len(m.nodes) != 2 {
+		t.Errorf("expected 2 nodes, got %d", len(m.nodes))
+	}
+	if m.textInput.Value() != "a" {
+		t.Errorf("expected first node in text input, got %q", m.textInput.Value())
+	}
+	if m.path != "tree.txt" {
+		t.Errorf("expected path 'tree.txt', got %q", m.path)
+	}
+	if m.dirty {
+		t.Error("expected clean model after load")
+	}
+}
+
+func TestUpdateMarksDirty(t *testing.T) {
+	m := New()
+
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}
+	newModel, _ := m.Update(msg)
+	updated := newModel.(Model)
+
+	if !updated.dirty {
+		t.Error("expected dirty model after typing")
+	}
+	if !strings.Contains(updated.buildTitle(), "[+]") {
+		t.Errorf("expected dirty marker in title, got %q", updated.buildTitle())
+	}
+}
+
+func TestUpdateSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.txt")
+	m := New(WithPath(path))
+	m.textInput.SetValue("saved")
+	m.dirty = true
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	updated := newModel.(Model)
+
+	if updated.dirty {
+		t.Errorf("expected clean model after save, message: %q", updated.message)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected file to be written: %v", err)
+	}
+	if string(data) != "saved\n" {
+		t.Errorf("expected 'saved\\n', got %q", string(data))
+	}
+}
+
+func TestUpdateSaveWithoutPath(t *testing.T) {
+	m := New()
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	updated := newModel.(Model)
+
+	if !strings.HasPrefix(updated.message, "Failed to save") {
+		t.Errorf("expected save failure message, got %q", updated.message)
+	}
+}
+
+func TestUpdateQuitDirtyPrompts(t *testing.T) {
+	m := New(WithPath(filepath.Join(t.TempDir(), "tree.txt")))
+	m.dirty = true
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlQ})
+	updated := newModel.(Model)
+
+	if cmd != nil {
+		t.Error("expected no quit command while confirming")
+	}
+	if !updated.quitting {
+		t.Fatal("expected save-on-quit prompt")
+	}
+
+	// Any other key cancels
+	newModel, cmd = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	updated = newModel.(Model)
+	if cmd != nil || updated.quitting {
+		t.Error("expected prompt to be cancelled")
+	}
+
+	// "n" quits without saving
+	newModel, _ = updated.Update(tea.KeyMsg{Type: tea.KeyCtrlQ})
+	_, cmd = newModel.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if cmd == nil {
+		t.Error("expected quit command after declining to save")
+	}
+}
+
+func TestUpdateQuitDirtyWithoutPath(t *testing.T) {
+	m := New()
+	m = press(m, runes("root")...)
+	if !m.dirty {
+		t.Fatal("expected typing to leave unsaved changes")
+	}
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlQ})
+	if cmd == nil || newModel.(Model).quitting {
+		t.Error("expected a tree without a file to quit without asking")
+	}
+}
+
+func TestUpdateQuitDirtySaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.txt")
+	m := New(WithPath(path))
+	m.dirty = true
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlQ})
+	_, cmd := newModel.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+
+	if cmd == nil {
+		t.Error("expected quit command after saving")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected file to be written: %v", err)
+	}
+}
+
+func TestQuitPrintsTree(t *testing.T) {
+	tr, _ := document.Parse("a\n  b  # note\n")
+	m := New(WithTree(tr), WithPrint())
+	m.dirty = true
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlQ})
+	if !m.confirmingPrint {
+		t.Fatal("expected print-on-quit prompt")
+	}
+
+	// Any other key cancels
+	m = press(m, runes("c")...)
+	if m.confirmingPrint || m.Output() != "" {
+		t.Error("expected prompt to be cancelled")
+	}
+
+	// Without a file, the tree is printed without asking to save
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlQ})
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if cmd == nil {
+		t.Error("expected quit command after choosing to print")
+	}
+	if got := newModel.(Model).Output(); got != "└── a\n    └── b  # note\n" {
+		t.Errorf("expected the rendered tree as output, got %q", got)
+	}
+}
+
+func TestQuitPrintsFoldedNodes(t *testing.T) {
+	tr, _ := document.Parse("a\n  b\nc\n")
+	tr.Root.Children[0].Expanded = false
+	m := New(WithTree(tr), WithPrint())
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlQ}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if got := m.Output(); got != "├── a\n│   └── b\n└── c\n" {
+		t.Errorf("expected folded nodes in the output, got %q", got)
+	}
+	if m.renderer.IncludeCollapsed {
+		t.Error("expected the preview to keep hiding folded nodes")
+	}
+}
+
+func TestQuitWithoutPrinting(t *testing.T) {
+	m := New(WithPrint())
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlQ})
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if cmd == nil {
+		t.Error("expected quit command after declining to print")
+	}
+	if got := newModel.(Model).Output(); got != "" {
+		t.Errorf("expected no output, got %q", got)
+	}
+}
+
+func TestQuitPrintsThenAsksToSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.txt")
+	m := New(WithPath(path), WithPrint())
+	m.dirty = true
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlQ}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if !m.quitting {
+		t.Fatal("expected save-on-quit prompt after the print prompt")
+	}
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if cmd == nil {
+		t.Error("expected quit command after saving")
+	}
+	if m.Output() == "" {
+		t.Error("expected the tree to be printed")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected file to be written: %v", err)
+	}
+}
+
+func TestUpdateSaveKeepsFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.txt")
+	tr, format := document.Parse("├── a\n└── b\n")
+	m := New(WithTree(tr), WithPath(path), WithFormat(format))
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected file to be written: %v", err)
+	}
+	if string(data) != "├── a\n└── b\n" {
+		t.Errorf("expected ASCII tree, got %q", string(data))
+	}
+}
+
+func TestUpdateCollapseExpand(t *testing.T) {
+	tr, _ := document.Parse("parent\n  child\nsibling\n")
+	m := New(WithTree(tr))
+	m.textInput.CursorStart()
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	updated := newModel.(Model)
+
+	if len(updated.nodes) != 2 {
+		t.Fatalf("expected 2 visible nodes after collapse, got %d", len(updated.nodes))
+	}
+	if !strings.Contains(updated.buildEditorView(40), "▸") || !strings.Contains(updated.buildEditorView(40), "[+1]") {
+		t.Errorf("expected fold marker in editor, got %q", updated.buildEditorView(40))
+	}
+
+	updated.textInput.CursorEnd()
+	newModel, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRight})
+	updated = newModel.(Model)
+
+	if len(updated.nodes) != 3 {
+		t.Errorf("expected 3 visible nodes after expand, got %d", len(updated.nodes))
+	}
+}
+
+func TestUpdateCollapseMovesTextCursor(t *testing.T) {
+	tr, _ := document.Parse("parent\n  child\n")
+	m := New(WithTree(tr))
+	m.setMode(ModeEdit)
+
+	// The text cursor is at the end, so left edits text instead of folding
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	updated := newModel.(Model)
+
+	if len(updated.nodes) != 2 {
+		t.Error("expected left to move the text cursor, not fold")
+	}
+}
+
+func TestUpdateCollapseLeafMovesToParent(t *testing.T) {
+	tr, _ := document.Parse("parent\n  child\n")
+	m := New(WithTree(tr))
+	m.moveCursor(1)
+	m.textInput.CursorStart()
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	updated := newModel.(Model)
+
+	if updated.cursor != 0 {
+		t.Errorf("expected cursor on parent, got %d", updated.cursor)
+	}
+}
+
+func TestUpdateFoldLevels(t *testing.T) {
+	tr, _ := document.Parse("a\n  b\n    c\n")
+	m := New(WithTree(tr))
+	m.moveCursor(2)
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}, Alt: true})
+	updated := newModel.(Model)
+
+	if len(updated.nodes) != 1 {
+		t.Errorf("expected 1 visible node after collapse all, got %d", len(updated.nodes))
+	}
+	if updated.currentNode().Text != "a" {
+		t.Errorf("expected cursor to move to visible ancestor, got %q", updated.currentNode().Text)
+	}
+
+	newModel, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}, Alt: true})
+	updated = newModel.(Model)
+	if len(updated.nodes) != 2 {
+		t.Errorf("expected 2 visible nodes at level 2, got %d", len(updated.nodes))
+	}
+
+	newModel, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'='}, Alt: true})
+	updated = newModel.(Model)
+	if len(updated.nodes) != 3 {
+		t.Errorf("expected 3 visible nodes after expand all, got %d", len(updated.nodes))
+	}
+}
+
+func TestUpdateToggleFolded(t *testing.T) {
+	tr, _ := document.Parse("parent\n  child\n")
+	tr.Root.Children[0].Expanded = false
+	m := New(WithTree(tr))
+
+	if strings.Contains(m.buildPreviewView(), "child") {
+		t.Error("expected folded content to be hidden from preview")
+	}
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}, Alt: true})
+	updated := newModel.(Model)
+
+	if !strings.Contains(updated.buildPreviewView(), "child") {
+		t.Error("expected folded content in preview after toggle")
+	}
+}
+
+func TestEditToggleFoldedKeepsWordForward(t *testing.T) {
+	tr, _ := document.Parse("hello world\n")
+	m := New(WithTree(tr))
+	m.setMode(ModeEdit)
+	m.textInput.CursorStart()
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}, Alt: true})
+	if m.textInput.Position() == 0 {
+		t.Error("expected Alt+F to move the cursor a word forward")
+	}
+	if m.renderer.IncludeCollapsed {
+		t.Error("expected Alt+F not to toggle folded content in edit mode")
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}, Alt: true})
+	if !m.renderer.IncludeCollapsed {
+		t.Error("expected Alt+Z to toggle folded content in edit mode")
+	}
+}
+
+func TestUndoRedoStructuralEdit(t *testing.T) {
+	tr, _ := document.Parse("a\nb\n")
+	m := New(WithTree(tr))
+	m.moveCursor(1)
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = newModel.(Model)
+	if m.currentNode().Depth() != 2 {
+		t.Fatal("expected b to be indented")
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	m = newModel.(Model)
+	if m.currentNode().Text != "b" || m.currentNode().Depth() != 1 {
+		t.Errorf("expected indent to be undone, got %q at depth %d", m.currentNode().Text, m.currentNode().Depth())
+	}
+	if m.message != "Undid indent" {
+		t.Errorf("expected undo message, got %q", m.message)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = newModel.(Model)
+	if m.currentNode().Depth() != 2 {
+		t.Error("expected indent to be redone")
+	}
+	if m.message != "Redid indent" {
+		t.Errorf("expected redo message, got %q", m.message)
+	}
+}
+
+func TestUndoDeleteRestoresSubtree(t *testing.T) {
+	tr, _ := document.Parse("a\n  a1\n  a2\nb\n")
+	m := New(WithTree(tr))
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	m = newModel.(Model)
+	if len(m.nodes) != 1 {
+		t.Fatalf("expected subtree to be deleted, got %d nodes", len(m.nodes))
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	m = newModel.(Model)
+	if len(m.nodes) != 4 {
+		t.Errorf("expected 4 nodes after undo, got %d", len(m.nodes))
+	}
+	if m.currentNode().Text != "a" {
+		t.Errorf("expected focus on restored node, got %q", m.currentNode().Text)
+	}
+}
+
+func TestUndoCoalescesTextEdits(t *testing.T) {
+	m := New()
+
+	for _, r := range "abc" {
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = newModel.(Model)
+	}
+	if m.currentNode().Text != "abc" {
+		t.Fatalf("expected 'abc', got %q", m.currentNode().Text)
+	}
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	m = newModel.(Model)
+
+	if m.currentNode().Text != "" {
+		t.Errorf("expected typing to be undone in one step, got %q", m.currentNode().Text)
+	}
+	if m.textInput.Value() != "" {
+		t.Errorf("expected text input to be synced, got %q", m.textInput.Value())
+	}
+	if m.message != "Undid text edit" {
+		t.Errorf("expected undo message, got %q", m.message)
+	}
+}
+
+func TestUndoNothing(t *testing.T) {
+	m := New()
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	m = newModel.(Model)
+
+	if m.message != "Nothing to undo" {
+		t.Errorf("expected 'Nothing to undo', got %q", m.message)
+	}
+}
+
+func TestNewStartsInNormalModeWithContent(t *testing.T) {
+	tr, _ := document.Parse("a\nb\n")
+	m := New(WithTree(tr))
+
+	if m.mode != ModeNormal {
+		t.Error("expected normal mode when opening an existing tree")
+	}
+	if m.textInput.Focused() {
+		t.Error("expected text input to be blurred in normal mode")
+	}
+}
+
+// foldedModel returns a model in normal mode on the tree in text, with the
+// first top-level node folded
+func foldedModel(text string) Model {
+	tr, _ := document.Parse(text)
+	tr.Root.Children[0].Expanded = false
+	return New(WithTree(tr))
+}
+
+// press sends a sequence of key presses to the model
+func press(m Model, keys ...tea.KeyMsg) Model {
+	for _, k := range keys {
+		newModel, _ := m.Update(k)
+		m = newModel.(Model)
+	}
+	return m
+}
+
+// runes returns one key message per character of s
+func runes(s string) []tea.KeyMsg {
+	var msgs []tea.KeyMsg
+	for _, r := range s {
+		msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return msgs
+}
+
+func TestNormalModeNavigation(t *testing.T) {
+	tr, _ := document.Parse("a\nb\nc\n")
+	m := New(WithTree(tr))
+
+	m = press(m, runes("j")...)
+	if m.cursor != 1 {
+		t.Errorf("expected cursor at 1 after j, got %d", m.cursor)
+	}
+	if m.currentNode().Text != "b" {
+		t.Error("expected j not to be typed into the node")
+	}
+
+	m = press(m, runes("G")...)
+	if m.cursor != 2 {
+		t.Errorf("expected cursor at 2 after G, got %d", m.cursor)
+	}
+
+	m = press(m, runes("gg")...)
+	if m.cursor != 0 {
+		t.Errorf("expected cursor at 0 after gg, got %d", m.cursor)
+	}
+}
+
+func TestNormalModeSequences(t *testing.T) {
+	tr, _ := document.Parse("a\nb\nc\n")
+	m := New(WithTree(tr))
+	m = press(m, runes("j")...)
+
+	m = press(m, runes(">>")...)
+	if m.currentNode().Depth() != 2 {
+		t.Errorf("expected >> to indent, got depth %d", m.currentNode().Depth())
+	}
+
+	m = press(m, runes("<<")...)
+	if m.currentNode().Depth() != 1 {
+		t.Errorf("expected << to unindent, got depth %d", m.currentNode().Depth())
+	}
+
+	m = press(m, runes("d")...)
+	if m.pending != "d" || len(m.nodes) != 3 {
+		t.Error("expected a single d to wait for the rest of the sequence")
+	}
+	m = press(m, runes("d")...)
+	if len(m.nodes) != 2 {
+		t.Errorf("expected dd to delete, got %d nodes", len(m.nodes))
+	}
+
+	m = press(m, runes("dx")...)
+	if m.pending != "" || len(m.nodes) != 2 {
+		t.Error("expected an unknown sequence to be discarded")
+	}
+}
+
+func TestNormalModeOpenLines(t *testing.T) {
+	tr, _ := document.Parse("a\nb\n")
+	m := New(WithTree(tr))
+
+	m = press(m, runes("o")...)
+	if m.mode != ModeEdit {
+		t.Fatal("expected o to enter edit mode")
+	}
+	if m.cursor != 1 || len(m.nodes) != 3 {
+		t.Errorf("expected new node below, cursor %d with %d nodes", m.cursor, len(m.nodes))
+	}
+
+	m = press(m, runes("x")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEscape})
+	if m.mode != ModeNormal {
+		t.Fatal("expected esc to return to normal mode")
+	}
+	if m.tree.Root.Children[1].Text != "x" {
+		t.Errorf("expected typed text to be saved, got %q", m.tree.Root.Children[1].Text)
+	}
+
+	m = press(m, runes("O")...)
+	if m.cursor != 1 || m.tree.Root.Children[2].Text != "x" {
+		t.Errorf("expected new node above, got cursor %d", m.cursor)
+	}
+}
+
+func TestNormalModeInsertAppend(t *testing.T) {
+	tr, _ := document.Parse("abc\n")
+	m := New(WithTree(tr))
+
+	m = press(m, runes("i")...)
+	if m.mode != ModeEdit || m.textInput.Position() != 0 {
+		t.Errorf("expected i to edit at start, mode %v position %d", m.mode, m.textInput.Position())
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEscape})
+	m = press(m, runes("a")...)
+	if m.mode != ModeEdit || m.textInput.Position() != 3 {
+		t.Errorf("expected a to edit at end, mode %v position %d", m.mode, m.textInput.Position())
+	}
+}
+
+func TestEscDoesNotQuit(t *testing.T) {
+	m := New()
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if cmd != nil {
+		t.Error("expected esc not to quit")
+	}
+}
+
+func TestViewShowsMode(t *testing.T) {
+	m := New()
+
+	if !strings.Contains(m.View(), "EDIT") {
+		t.Error("expected edit mode in status line")
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEscape})
+	if !strings.Contains(m.View(), "NORMAL") {
+		t.Error("expected normal mode in status line")
+	}
+}
+
+func TestUpdateMoveUpDown(t *testing.T) {
+	tr, _ := document.Parse("a\nb\n  b1\nc\n")
+	m := New(WithTree(tr))
+	m = press(m, runes("G")...)
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyUp, Alt: true})
+	if m.tree.Root.Children[1].Text != "c" {
+		t.Errorf("expected c to move above b, got %q", render.Outline(m.tree))
+	}
+	if m.currentNode().Text != "c" {
+		t.Errorf("expected cursor to follow the moved node, got %q", m.currentNode().Text)
+	}
+
+	m = press(m, runes("J")...)
+	if m.tree.Root.Children[2].Text != "c" {
+		t.Errorf("expected c to move back below b, got %q", render.Outline(m.tree))
+	}
+	if m.currentNode().Text != "c" {
+		t.Errorf("expected cursor to follow the moved node, got %q", m.currentNode().Text)
+	}
+
+	m = press(m, runes("u")...)
+	if m.tree.Root.Children[1].Text != "c" || m.message != "Undid move down" {
+		t.Errorf("expected move to be undoable, message %q", m.message)
+	}
+}
+
+func TestYankPaste(t *testing.T) {
+	tr, _ := document.Parse("a\n  a1\nb\n")
+	m := New(WithTree(tr))
+
+	m = press(m, runes("yy")...)
+	if m.message != "Yanked 2 node(s)" {
+		t.Errorf("expected yank message, got %q", m.message)
+	}
+
+	m = press(m, runes("Gp")...)
+	expected := "a\n  a1\nb\na\n  a1\n"
+	if output := render.Outline(m.tree); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+	if m.cursor != 3 {
+		t.Errorf("expected cursor on pasted node, got %d", m.cursor)
+	}
+
+	original, pasted := m.tree.Root.Children[0], m.tree.Root.Children[2]
+	if original.ID == pasted.ID || original.Children[0].ID == pasted.Children[0].ID {
+		t.Error("expected pasted nodes to get fresh IDs")
+	}
+
+	m = press(m, runes("p")...)
+	if m.tree.Root.Children[3].ID == pasted.ID {
+		t.Error("expected every paste to get fresh IDs")
+	}
+}
+
+func TestCutPasteAboveAndChild(t *testing.T) {
+	tr, _ := document.Parse("a\nb\nc\n")
+	m := New(WithTree(tr))
+
+	m = press(m, runes("dd")...)
+	if output := render.Outline(m.tree); output != "b\nc\n" {
+		t.Fatalf("expected a to be cut, got %q", output)
+	}
+
+	m = press(m, runes("jP")...)
+	if output := render.Outline(m.tree); output != "b\na\nc\n" {
+		t.Errorf("expected a pasted above c, got %q", output)
+	}
+
+	m = press(m, runes("G>p")...)
+	if output := render.Outline(m.tree); output != "b\na\nc\n  a\n" {
+		t.Errorf("expected a pasted as child of c, got %q", output)
+	}
+}
+
+func TestCutOnlyNode(t *testing.T) {
+	tr, _ := document.Parse("a  # note\n  b\n  c\n")
+	m := New(WithTree(tr))
+
+	m = press(m, runes("dd")...)
+	if output := render.Outline(m.tree); output != "\n" {
+		t.Errorf("expected a single empty node to be left, got %q", output)
+	}
+	if m.message != "Cut 3 node(s)" {
+		t.Errorf("unexpected message %q", m.message)
+	}
+
+	m = press(m, runes("p")...)
+	if output := render.Outline(m.tree); output != "\na  # note\n  b\n  c\n" {
+		t.Errorf("expected the subtree to be pasted once, got %q", output)
+	}
+}
+
+func TestPasteEmptyRegister(t *testing.T) {
+	tr, _ := document.Parse("a\n")
+	m := New(WithTree(tr))
+
+	m = press(m, runes("p")...)
+
+	if m.message != "Register is empty" || len(m.nodes) != 1 {
+		t.Errorf("expected nothing to paste, got %q", m.message)
+	}
+}
+
+// paste returns the key message of a bracketed paste
+func paste(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text), Paste: true}
+}
+
+func TestPasteLinesAsSubtree(t *testing.T) {
+	tests := map[string]string{
+		"outline":  "x\n  x1\n    x2\ny\n",
+		"markdown": "* x\n    * x1\n        * x2\n* y\n",
+		"ascii":    "├── x\n│   └── x1\n│       └── x2\n└── y\n",
+	}
+
+	for name, text := range tests {
+		tr, _ := document.Parse("a\n  a1\nb\n")
+		m := New(WithTree(tr))
+
+		m = press(m, paste(text))
+		expected := "a\n  a1\nx\n  x1\n    x2\ny\nb\n"
+		if output := render.Outline(m.tree); output != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, output)
+		}
+		if m.currentNode().Text != "x" || m.message != "Pasted 4 node(s)" {
+			t.Errorf("%s: expected the first pasted node to be focused, got %q (%q)", name, m.currentNode().Text, m.message)
+		}
+
+		m = press(m, runes("u")...)
+		if output := render.Outline(m.tree); output != "a\n  a1\nb\n" {
+			t.Errorf("%s: expected paste to be undoable, got %q", name, output)
+		}
+	}
+}
+
+func TestPasteLinesReplacesEmptyNode(t *testing.T) {
+	m := New()
+	if m.mode != ModeEdit {
+		t.Fatal("expected a new tree to start in edit mode")
+	}
+
+	m = press(m, paste("a\r\n  b\r\n"))
+	if output := render.Outline(m.tree); output != "a\n  b\n" {
+		t.Errorf("expected the empty node to be replaced, got %q", output)
+	}
+	if m.textInput.Value() != "a" {
+		t.Errorf("expected to keep editing the first pasted node, got %q", m.textInput.Value())
+	}
+}
+
+func TestPasteLinesWithCarriageReturns(t *testing.T) {
+	tr, _ := document.Parse("a\nb\n")
+	m := New(WithTree(tr))
+	m.setMode(ModeNormal)
+
+	m = press(m, paste("x\r  y\rz\r"))
+	if output := render.Outline(m.tree); output != "a\nx\n  y\nz\nb\n" {
+		t.Errorf("expected lines separated by \\r to become nodes, got %q", output)
+	}
+}
+
+func TestPasteSingleLineEditsText(t *testing.T) {
+	m := New()
+
+	m = press(m, paste("hello\n"))
+	if len(m.tree.Root.Children) != 1 {
+		t.Errorf("expected a single line not to add nodes, got %q", render.Outline(m.tree))
+	}
+}
+
+func TestCycleStyle(t *testing.T) {
+	tr, _ := document.Parse("a\nb\n")
+	m := New(WithTree(tr))
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}, Alt: true})
+
+	if m.message != "Style: ascii" {
+		t.Errorf("expected style message, got %q", m.message)
+	}
+	if !strings.HasPrefix(m.buildPreviewView(), "|-- a") {
+		t.Errorf("expected preview in ascii style, got %q", m.buildPreviewView())
+	}
+	if m.dirty {
+		t.Error("changing the style should not modify the tree")
+	}
+}
+
+func TestWithFormatUsesFileStyle(t *testing.T) {
+	tr, format := document.Parse("┣━━ a\n┗━━ b\n")
+	m := New(WithTree(tr), WithFormat(format))
+
+	if !strings.HasPrefix(m.buildPreviewView(), "┣━━ a") {
+		t.Errorf("expected preview in the file's style, got %q", m.buildPreviewView())
+	}
+}
+
+func TestWithStyle(t *testing.T) {
+	style, _ := render.LookupStyle("compact")
+	m := New(WithStyle(style))
+
+	if m.renderer.Style != style {
+		t.Error("expected renderer to use the given style")
+	}
+}
+
+func TestCheckSplit(t *testing.T) {
+	for _, tt := range []struct {
+		percent int
+		ok      bool
+	}{{20, true}, {50, true}, {80, true}, {10, false}, {81, false}} {
+		if err := CheckSplit(tt.percent); (err == nil) != tt.ok {
+			t.Errorf("CheckSplit(%d) = %v", tt.percent, err)
+		}
+	}
+}
+
+func TestWithCommentOptions(t *testing.T) {
+	m := New(WithCommentAlign(render.CommentAlignInline), WithCommentDelimiter("--"))
+
+	if m.renderer.CommentAlign != render.CommentAlignInline || m.renderer.CommentDelimiter != "--" {
+		t.Error("expected renderer to use the given comment options")
+	}
+}
+
+func TestWithSplit(t *testing.T) {
+	for _, tt := range []struct{ percent, expected int }{{30, 48}, {5, 32}, {95, 128}} {
+		m := New(WithSplit(tt.percent))
+		newModel, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 12})
+		m = newModel.(Model)
+
+		if got := m.editorPaneWidth(); got != tt.expected {
+			t.Errorf("WithSplit(%d): editor pane is %d wide, expected %d", tt.percent, got, tt.expected)
+		}
+		editor := strings.Split(m.View(), "\n")[1]
+		if !strings.HasPrefix(editor, "╭"+strings.Repeat("─", tt.expected-2)+"╮") {
+			t.Errorf("WithSplit(%d): expected the editor border to be %d wide, got %q", tt.percent, tt.expected, editor)
+		}
+		for _, line := range strings.Split(m.View(), "\n") {
+			if w := lipgloss.Width(line); w > m.width {
+				t.Errorf("WithSplit(%d): line is %d cells wide, more than %d", tt.percent, w, m.width)
+			}
+		}
+	}
+}
+
+func TestCopyAsPrompt(t *testing.T) {
+	m := New()
+	m.setMode(ModeNormal)
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}, Alt: true})
+	if !m.choosingFormat {
+		t.Fatal("expected Alt+E to ask for a format")
+	}
+	if !strings.Contains(m.message, "4:json") {
+		t.Errorf("expected the prompt to list formats, got %q", m.message)
+	}
+
+	// Any key other than a format number cancels
+	m = press(m, runes("j")...)
+	if m.choosingFormat {
+		t.Error("expected the prompt to be cancelled")
+	}
+	if m.copied {
+		t.Error("expected nothing to be copied")
+	}
+}
+
+func TestCopyAsChoosesFormat(t *testing.T) {
+	cb := &fakeClipboard{name: "clipboard"}
+	m := New(WithClipboard(cb))
+	m.setMode(ModeNormal)
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}, Alt: true})
+	m = press(m, runes("4")...)
+	if m.choosingFormat {
+		t.Error("expected the prompt to be answered")
+	}
+	if m.message != "Copied to clipboard as json!" {
+		t.Errorf("unexpected message %q", m.message)
+	}
+	if !strings.HasPrefix(cb.text, "[") {
+		t.Errorf("expected JSON to be copied, got %q", cb.text)
+	}
+}
+
+// fakeClipboard records copied text, or fails. It mirrors the fake backend
+// in the clipboard package's tests, which other packages can't import.
+type fakeClipboard struct {
+	name string
+	err  error
+	text string
+}
+
+func (f *fakeClipboard) Write(text string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.text = text
+	return nil
+}
+
+func (f *fakeClipboard) Name() string {
+	return f.name
+}
+
+func TestCopyUsesClipboardBackend(t *testing.T) {
+	tr, _ := document.Parse("a\n  b\n")
+	cb := &fakeClipboard{name: "/tmp/clipboard.txt"}
+	m := New(WithTree(tr), WithClipboard(cb))
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlC})
+	if cb.text != "└── a\n    └── b\n" {
+		t.Errorf("expected the rendered tree to be copied, got %q", cb.text)
+	}
+	if !m.copied || m.message != "Copied to /tmp/clipboard.txt!" {
+		t.Errorf("expected the message to name the destination, got %q", m.message)
+	}
+
+	cb.err = errors.New("no xclip")
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlC})
+	if m.copied || m.message != "Failed to copy: no xclip" {
+		t.Errorf("expected the copy to fail, got %q", m.message)
+	}
+}
+
+func TestFormatMenu(t *testing.T) {
+	expected := "1:ascii 2:outline 3:markdown 4:json 5:yaml 6:dot 7:mermaid 8:mindmap"
+	if menu := formatMenu(); menu != expected {
+		t.Errorf("expected %q, got %q", expected, menu)
+	}
+}
+
+func TestPreviewHighlightsCurrentNode(t *testing.T) {
+	tr, _ := document.Parse("a\n  b\nc\n")
+	m := New(WithTree(tr))
+	m = press(m, runes("j")...)
+
+	lines := strings.Split(m.buildPreviewView(), "\n")
+	if expected := "│   └── " + selectedStyle.Render("b"); lines[1] != expected {
+		t.Errorf("expected %q, got %q", expected, lines[1])
+	}
+
+	// A node hidden in the preview highlights its nearest shown ancestor
+	m.tree.Root.Children[0].Expanded = false
+	m.renderer.IncludeCollapsed = false
+	if line := m.previewLine(m.renderer.Lines(m.tree)); line != 0 {
+		t.Errorf("expected line 0 for the folded parent, got %d", line)
+	}
+}
+
+func TestViewFitsWideText(t *testing.T) {
+	// Wide enough for the help line, but not for the node text
+	long := strings.Repeat("日本語のファイル名", 12)
+	tr, _ := document.Parse("🚀 " + long + "\n  " + long + "\n")
+	for _, mode := range []Mode{ModeNormal, ModeEdit} {
+		m := New(WithTree(tr))
+		m.setMode(mode)
+		newModel, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 12})
+		m = newModel.(Model)
+
+		lines := strings.Split(m.View(), "\n")
+		if len(lines) != m.height {
+			t.Errorf("mode %v: expected %d lines without wrapping, got %d", mode, m.height, len(lines))
+		}
+		for _, line := range lines {
+			if w := lipgloss.Width(line); w > m.width {
+				t.Errorf("mode %v: line is %d cells wide, more than %d: %q", mode, w, m.width, line)
+			}
+		}
+	}
+}
+
+func TestTruncateByDisplayWidth(t *testing.T) {
+	if got := truncate("日本語\nabc", 4); got != "日…\nabc" {
+		t.Errorf("expected wide text cut by cells, got %q", got)
+	}
+}
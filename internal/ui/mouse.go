@@ -0,0 +1,142 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// paneTop is the screen row of the first line inside the panes, below the
+// title and the top border
+const paneTop = 2
+
+// dropTarget is where a node dragged with the mouse will be moved
+type dropTarget struct {
+	node *tree.Node
+	into bool // As the last child rather than the next sibling
+}
+
+// handleMouse scrolls the pane under the mouse wheel, moves the cursor to
+// clicked rows and moves rows dragged onto other rows
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch msg.Action {
+	case tea.MouseActionPress:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.scrollWheel(msg.X, -wheelLines)
+		case tea.MouseButtonWheelDown:
+			m.scrollWheel(msg.X, wheelLines)
+		case tea.MouseButtonLeft:
+			if m.prompting() {
+				break
+			}
+			if msg.X >= m.editorPaneWidth() {
+				m.clickPreview(m.previewOffset + msg.Y - paneTop)
+			} else {
+				m.clickEditor(m.editorOffset + msg.Y - paneTop)
+			}
+		}
+	case tea.MouseActionMotion:
+		if m.dragNode != nil {
+			m.dropTarget = m.dropTargetAt(msg.X, msg.Y)
+		}
+	case tea.MouseActionRelease:
+		if m.dragNode != nil && m.dropTarget != nil {
+			m.drop()
+		}
+		m.dragNode = nil
+		m.dropTarget = nil
+	}
+	return m, nil
+}
+
+// prompting reports whether a prompt is waiting for keys
+func (m Model) prompting() bool {
+	return m.quitting || m.confirmingPrint || m.choosingFormat || m.searching || m.filtering || m.commenting || m.showingHelp
+}
+
+// scrollWheel scrolls the pane at column x by delta lines
+func (m *Model) scrollWheel(x, delta int) {
+	h := m.paneHeight()
+	if x >= m.editorPaneWidth() {
+		// The preview scrolls freely until the cursor moves
+		total := len(m.renderer.Lines(m.exportTree()))
+		m.previewOffset = clampOffset(m.previewOffset+delta, total, h)
+		return
+	}
+
+	// Scrolling the editor drags the cursor along to stay visible, so it
+	// stays put while the current node's comment is being edited
+	if m.commenting {
+		return
+	}
+	m.editorOffset = clampOffset(m.editorOffset+delta, len(m.nodes), h)
+	cursor := max(min(m.cursor, m.editorOffset+h-1), m.editorOffset)
+	if cursor != m.cursor {
+		m.saveCurrentEdit()
+		m.cursor = min(cursor, len(m.nodes)-1)
+		m.syncTextInput()
+	}
+	m.scrollPreview()
+}
+
+// clickEditor moves the cursor to an editor row and starts dragging it
+func (m *Model) clickEditor(index int) {
+	if index < 0 || index >= len(m.nodes) {
+		return
+	}
+	m.saveCurrentEdit()
+	m.cursor = index
+	m.syncTextInput()
+	m.scrollPreview()
+	m.dragNode = m.nodes[index]
+}
+
+// clickPreview moves the cursor to the node shown on a preview line,
+// expanding its ancestors if it is folded in the editor
+func (m *Model) clickPreview(index int) {
+	lines := m.renderer.Lines(m.exportTree())
+	if index < 0 || index >= len(lines) {
+		return
+	}
+	node := m.tree.Find(lines[index].NodeID)
+	if node == nil {
+		return
+	}
+	m.saveCurrentEdit()
+	m.tree.Reveal(node)
+	m.refreshNodes()
+	m.focusNode(node)
+	m.scrollEditor()
+	m.scrollPreview()
+}
+
+// dropTargetAt returns where the dragged node would be dropped at a screen
+// position. Dropping on a row's text makes the node its last child, and
+// dropping left of the text makes it the next sibling.
+func (m Model) dropTargetAt(x, y int) *dropTarget {
+	index := m.editorOffset + y - paneTop
+	if x >= m.editorPaneWidth() || index < 0 || index >= len(m.nodes) {
+		return nil
+	}
+	node := m.nodes[index]
+	if m.dragNode.Contains(node) {
+		return nil
+	}
+	// Border, padding, indentation and bullet come before the text
+	textColumn := 2 + 2*(node.Depth()-1) + 2
+	return &dropTarget{node: node, into: x >= textColumn}
+}
+
+// drop moves the dragged node to the drop target
+func (m *Model) drop() {
+	target := m.dropTarget
+	m.focusNode(m.dragNode)
+	m.moveNode(func(n *tree.Node) bool {
+		if target.into {
+			return m.tree.MoveInto(n, target.node)
+		}
+		return m.tree.MoveAfter(n, target.node)
+	}, "move")
+	m.scrollEditor()
+	m.scrollPreview()
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/radish-miyazaki/ttree/internal/document"
+)
+
+// mouse sends a mouse event to m
+func mouse(m Model, x, y int, button tea.MouseButton, action tea.MouseAction) Model {
+	newModel, _ := m.Update(tea.MouseMsg{X: x, Y: y, Button: button, Action: action})
+	return newModel.(Model)
+}
+
+// drag drags the editor row at fromY to (x, toY)
+func drag(m Model, fromY, x, toY int) Model {
+	m = mouse(m, 5, fromY, tea.MouseButtonLeft, tea.MouseActionPress)
+	m = mouse(m, x, toY, tea.MouseButtonLeft, tea.MouseActionMotion)
+	return mouse(m, x, toY, tea.MouseButtonNone, tea.MouseActionRelease)
+}
+
+func TestClickPreviewLine(t *testing.T) {
+	tr, _ := document.Parse("a\n  b\nc\n")
+	tr.Root.Children[0].Expanded = false
+	m := New(WithTree(tr))
+	m.renderer.IncludeCollapsed = true
+
+	// The preview shows a, b and c on rows 2 to 4
+	click := tea.MouseMsg{X: 60, Y: paneTop + 1, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+	newModel, _ := m.Update(click)
+	m = newModel.(Model)
+	if node := m.currentNode(); node.Text != "b" {
+		t.Errorf("expected cursor on b, got %q", node.Text)
+	}
+	if !m.tree.Root.Children[0].Expanded {
+		t.Error("expected the folded parent to be expanded")
+	}
+}
+
+func TestClickEditorRow(t *testing.T) {
+	tr, _ := document.Parse("a\nb\nc\n")
+	m := New(WithTree(tr))
+	m = mouse(m, 5, paneTop+2, tea.MouseButtonLeft, tea.MouseActionPress)
+	m = mouse(m, 5, paneTop+2, tea.MouseButtonNone, tea.MouseActionRelease)
+	if node := m.currentNode(); node.Text != "c" {
+		t.Errorf("expected cursor on c, got %q", node.Text)
+	}
+	if m.dirty {
+		t.Error("expected a click not to change the tree")
+	}
+}
+
+func TestDragShowsDropIndicator(t *testing.T) {
+	tr, _ := document.Parse("a\nb\nc\n")
+	m := New(WithTree(tr))
+	m = mouse(m, 5, paneTop, tea.MouseButtonLeft, tea.MouseActionPress)
+	m = mouse(m, 5, paneTop+2, tea.MouseButtonLeft, tea.MouseActionMotion)
+	if !strings.Contains(m.buildEditorView(40), "move into") {
+		t.Errorf("expected a drop indicator, got:\n%s", m.buildEditorView(40))
+	}
+	m = mouse(m, 1, paneTop+2, tea.MouseButtonLeft, tea.MouseActionMotion)
+	if !strings.Contains(m.buildEditorView(40), "move below") {
+		t.Errorf("expected a sibling drop indicator, got:\n%s", m.buildEditorView(40))
+	}
+}
+
+func TestDragIntoRow(t *testing.T) {
+	tr, _ := document.Parse("a\nb\nc\n")
+	m := New(WithTree(tr))
+	m = drag(m, paneTop, 5, paneTop+2)
+	if got := document.Write(m.tree, document.FormatOutline); got != "b\nc\n  a\n" {
+		t.Errorf("expected a under c, got %q", got)
+	}
+	if node := m.currentNode(); node.Text != "a" {
+		t.Errorf("expected cursor to follow a, got %q", node.Text)
+	}
+	if m.dropTarget != nil || m.dragNode != nil {
+		t.Error("expected the drag to end")
+	}
+
+	m = press(m, runes("u")...)
+	if got := document.Write(m.tree, document.FormatOutline); got != "a\nb\nc\n" {
+		t.Errorf("expected undo to restore the tree, got %q", got)
+	}
+}
+
+func TestDragBelowRow(t *testing.T) {
+	tr, _ := document.Parse("a\n  a1\nb\nc\n")
+	m := New(WithTree(tr))
+	m = drag(m, paneTop+3, 1, paneTop)
+	if got := document.Write(m.tree, document.FormatOutline); got != "a\n  a1\nc\nb\n" {
+		t.Errorf("expected c after a, got %q", got)
+	}
+}
+
+func TestDragIntoOwnSubtree(t *testing.T) {
+	tr, _ := document.Parse("a\n  a1\nb\n")
+	m := New(WithTree(tr))
+	m = drag(m, paneTop, 7, paneTop+1)
+	if got := document.Write(m.tree, document.FormatOutline); got != "a\n  a1\nb\n" {
+		t.Errorf("expected the tree to be unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+package ui
+
+import "strings"
+
+// wheelLines is how far one mouse wheel step scrolls
+const wheelLines = 3
+
+// paneHeight returns the number of lines visible in each pane
+func (m Model) paneHeight() int {
+	height := m.height - 5
+	if m.commenting {
+		// The comment editor takes the place of the status line
+		height -= commentHeight - 1
+	}
+	return max(height, 1)
+}
+
+// The editor pane's share of the width is kept within these percentages
+const (
+	minSplit = 20
+	maxSplit = 80
+)
+
+// editorPaneWidth returns the outer width of the editor pane
+func (m Model) editorPaneWidth() int {
+	// The editor's content width (see View) plus its border
+	return m.width * m.split / 100
+}
+
+// scrollEditor keeps the cursor within the visible part of the editor
+func (m *Model) scrollEditor() {
+	m.editorOffset = scrollTo(m.editorOffset, m.cursor, len(m.nodes), m.paneHeight())
+}
+
+// scrollPreview keeps the current node's line within the visible part of
+// the preview
+func (m *Model) scrollPreview() {
+	lines := m.renderer.Lines(m.exportTree())
+	line := m.previewLine(lines)
+	if line < 0 {
+		line = m.previewOffset
+	}
+	m.previewOffset = scrollTo(m.previewOffset, line, len(lines), m.paneHeight())
+}
+
+// scrollTo returns the offset of a window of height lines over total lines
+// that is closest to offset while showing line
+func scrollTo(offset, line, total, height int) int {
+	if line < offset {
+		offset = line
+	}
+	if line >= offset+height {
+		offset = line - height + 1
+	}
+	return clampOffset(offset, total, height)
+}
+
+// clampOffset keeps a window of height lines within total lines
+func clampOffset(offset, total, height int) int {
+	return max(min(offset, total-height), 0)
+}
+
+// pageCursor moves the cursor and the editor by delta lines
+func (m *Model) pageCursor(delta int) {
+	m.saveCurrentEdit()
+	m.cursor = max(min(m.cursor+delta, len(m.nodes)-1), 0)
+	m.editorOffset = clampOffset(m.editorOffset+delta, len(m.nodes), m.paneHeight())
+	m.syncTextInput()
+}
+
+// window returns the height lines of s starting at offset
+func window(s string, offset, height int) string {
+	lines := strings.Split(s, "\n")
+	if offset > len(lines) {
+		offset = len(lines)
+	}
+	lines = lines[offset:]
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return strings.Join(lines, "\n")
+}
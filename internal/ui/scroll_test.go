@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/radish-miyazaki/ttree/internal/document"
+)
+
+// scrollModel returns a 10-line-tall model in normal mode on 30 nodes
+func scrollModel() Model {
+	var sb strings.Builder
+	for i := range 30 {
+		fmt.Fprintf(&sb, "node%d\n", i)
+	}
+	tr, _ := document.Parse(sb.String())
+	m := New(WithTree(tr))
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 10})
+	return newModel.(Model)
+}
+
+func TestScrollTo(t *testing.T) {
+	tests := []struct {
+		offset, line, total, height int
+		expected                    int
+	}{
+		{0, 3, 30, 5, 0},
+		{0, 5, 30, 5, 1},
+		{10, 4, 30, 5, 4},
+		{28, 29, 30, 5, 25},
+		{5, 2, 3, 5, 0},
+	}
+	for _, tt := range tests {
+		if got := scrollTo(tt.offset, tt.line, tt.total, tt.height); got != tt.expected {
+			t.Errorf("scrollTo(%d, %d, %d, %d) = %d, want %d",
+				tt.offset, tt.line, tt.total, tt.height, got, tt.expected)
+		}
+	}
+}
+
+func TestEditorFollowsCursor(t *testing.T) {
+	m := scrollModel()
+	m = press(m, runes(strings.Repeat("j", 7))...)
+	if m.editorOffset != 3 {
+		t.Errorf("expected editor offset 3, got %d", m.editorOffset)
+	}
+	view := m.buildEditorView(40)
+	if strings.Contains(view, "node2\n") || !strings.Contains(view, "node7") {
+		t.Errorf("expected editor to show node3 to node7, got:\n%s", view)
+	}
+	if m.previewOffset != 3 {
+		t.Errorf("expected preview to follow the cursor to offset 3, got %d", m.previewOffset)
+	}
+
+	m = press(m, runes("G")...)
+	if m.editorOffset != 25 {
+		t.Errorf("expected editor offset 25 at the bottom, got %d", m.editorOffset)
+	}
+}
+
+func TestPageDownAndUp(t *testing.T) {
+	m := scrollModel()
+	m = press(m, tea.KeyMsg{Type: tea.KeyPgDown})
+	if m.cursor != 5 || m.editorOffset != 5 {
+		t.Errorf("expected cursor and offset 5, got %d and %d", m.cursor, m.editorOffset)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyPgDown}, tea.KeyMsg{Type: tea.KeyPgDown},
+		tea.KeyMsg{Type: tea.KeyPgDown}, tea.KeyMsg{Type: tea.KeyPgDown},
+		tea.KeyMsg{Type: tea.KeyPgDown}, tea.KeyMsg{Type: tea.KeyPgDown})
+	if m.cursor != 29 || m.editorOffset != 25 {
+		t.Errorf("expected cursor 29 and offset 25, got %d and %d", m.cursor, m.editorOffset)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyPgUp})
+	if m.cursor != 24 || m.editorOffset != 20 {
+		t.Errorf("expected cursor 24 and offset 20, got %d and %d", m.cursor, m.editorOffset)
+	}
+}
+
+func TestMouseWheelScrollsEditor(t *testing.T) {
+	m := scrollModel()
+	wheel := tea.MouseMsg{X: 5, Y: 5, Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress}
+	newModel, _ := m.Update(wheel)
+	m = newModel.(Model)
+	if m.editorOffset != 3 {
+		t.Errorf("expected editor offset 3, got %d", m.editorOffset)
+	}
+	if m.cursor != 3 {
+		t.Errorf("expected cursor dragged to 3, got %d", m.cursor)
+	}
+	if m.textInput.Value() != "node3" {
+		t.Errorf("expected text input to follow the cursor, got %q", m.textInput.Value())
+	}
+}
+
+func TestMouseWheelScrollsPreview(t *testing.T) {
+	m := scrollModel()
+	wheel := tea.MouseMsg{X: 60, Y: 5, Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress}
+	newModel, _ := m.Update(wheel)
+	m = newModel.(Model)
+	if m.previewOffset != 3 || m.editorOffset != 0 || m.cursor != 0 {
+		t.Errorf("expected only the preview to scroll, got preview %d, editor %d, cursor %d",
+			m.previewOffset, m.editorOffset, m.cursor)
+	}
+
+	// Moving the cursor brings the preview back to it
+	m = press(m, runes("j")...)
+	if m.previewOffset != 1 {
+		t.Errorf("expected preview to return to the cursor, got %d", m.previewOffset)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	if got := window("a\nb\nc\nd", 1, 2); got != "b\nc" {
+		t.Errorf("expected b and c, got %q", got)
+	}
+	if got := window("a\nb", 5, 2); got != "" {
+		t.Errorf("expected nothing past the end, got %q", got)
+	}
+}
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/radish-miyazaki/ttree/internal/search"
+	"github.com/radish-miyazaki/ttree/internal/tree"
+)
+
+// startSearch opens the search prompt
+func (m *Model) startSearch() tea.Cmd {
+	m.saveCurrentEdit()
+	m.textInput.Blur()
+	m.searching = true
+	m.searchOrigin = m.currentNode()
+	m.searchInput.SetValue("")
+	return m.searchInput.Focus()
+}
+
+// endSearch closes the search prompt
+func (m *Model) endSearch() {
+	m.searching = false
+	m.searchInput.Blur()
+	if m.mode == ModeEdit {
+		m.textInput.Focus()
+	}
+}
+
+// handleSearchKey handles keys typed into the search prompt.
+// Matches are highlighted and the cursor jumps to the first one as the
+// query is typed.
+func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := m.keys.forMode(m.mode)
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.endSearch()
+		m.matcher = nil
+		if m.searchOrigin != nil {
+			m.focusNode(m.searchOrigin)
+		}
+		return m, nil
+	case msg.Type == tea.KeyEnter:
+		m.endSearch()
+		m.updateSearch()
+		if m.matcher != nil && m.message == "" {
+			m.message = fmt.Sprintf("%d matches", len(m.matcher.Nodes(m.tree)))
+		}
+		return m, nil
+	case matches(msg, keys.ToggleRegex):
+		m.searchOpts.Regex = !m.searchOpts.Regex
+		m.updateSearch()
+		return m, nil
+	case matches(msg, keys.ToggleCase):
+		m.searchOpts.IgnoreCase = !m.searchOpts.IgnoreCase
+		m.updateSearch()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	query := m.searchInput.Value()
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	if m.searchInput.Value() != query {
+		m.updateSearch()
+	}
+	return m, cmd
+}
+
+// updateSearch compiles the query and moves to the first match at or
+// after the node the search started from
+func (m *Model) updateSearch() {
+	m.matcher = nil
+	query := m.searchInput.Value()
+	if query == "" {
+		if m.searchOrigin != nil {
+			m.focusNode(m.searchOrigin)
+		}
+		return
+	}
+	matcher, err := search.Compile(query, m.searchOpts)
+	if err != nil {
+		m.message = "Invalid pattern: " + err.Error()
+		return
+	}
+	m.matcher = matcher
+	if !m.jumpToMatch(m.searchOrigin, true, true) {
+		m.message = "Pattern not found: " + query
+	}
+}
+
+// searchNext moves to the next or previous match of the last search
+func (m *Model) searchNext(forward bool) {
+	if m.matcher == nil {
+		m.message = "No previous search"
+		return
+	}
+	m.saveCurrentEdit()
+	if !m.jumpToMatch(m.currentNode(), forward, false) {
+		m.message = "Pattern not found: " + m.searchInput.Value()
+		return
+	}
+	found := m.matcher.Nodes(m.tree)
+	for i, n := range found {
+		if n == m.currentNode() {
+			m.message = fmt.Sprintf("Match %d of %d", i+1, len(found))
+		}
+	}
+}
+
+// jumpToMatch focuses the first matching node after from, wrapping around
+// the tree and expanding collapsed ancestors. If inclusive, from itself is
+// checked first.
+func (m *Model) jumpToMatch(from *tree.Node, forward, inclusive bool) bool {
+	all := m.tree.Flatten()
+	if m.filter != nil {
+		// Only nodes shown by the filter can be reached
+		all = m.nodes
+	}
+	if len(all) == 0 {
+		return false
+	}
+	start := max(indexOf(all, from), 0)
+	offset := 1
+	if inclusive {
+		offset = 0
+	}
+	for i := 0; i < len(all); i++ {
+		idx := (start + i + offset) % len(all)
+		if !forward {
+			idx = ((start-i-offset)%len(all) + len(all)) % len(all)
+		}
+		if n := all[idx]; m.matcher.Match(n.Text) {
+			m.tree.Reveal(n)
+			m.refreshNodes()
+			m.focusNode(n)
+			return true
+		}
+	}
+	return false
+}
+
+// newSearchInput returns the text input used for the search prompt
+func newSearchInput() textinput.Model {
+	si := textinput.New()
+	si.Prompt = "/"
+	si.CharLimit = 256
+	return si
+}
@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchTree is src, holding main.go and main_test.go, followed by README.md
+const searchTree = "src\n  main.go\n  main_test.go\nREADME.md\n"
+
+func TestSearchJumpsAndExpands(t *testing.T) {
+	m := foldedModel(searchTree)
+	m = press(m, runes("/main")...)
+	if !m.searching {
+		t.Fatal("expected the search prompt to be open")
+	}
+	if node := m.currentNode(); node.Text != "main.go" {
+		t.Errorf("expected cursor on main.go, got %q", node.Text)
+	}
+	if !m.tree.Root.Children[0].Expanded {
+		t.Error("expected the folded parent to be expanded")
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.searching {
+		t.Error("expected Enter to close the prompt")
+	}
+	if m.message != "2 matches" {
+		t.Errorf("expected '2 matches', got %q", m.message)
+	}
+}
+
+func TestSearchNextAndPrev(t *testing.T) {
+	m := foldedModel(searchTree)
+	m = press(m, runes("/main")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	m = press(m, runes("n")...)
+	if node := m.currentNode(); node.Text != "main_test.go" {
+		t.Errorf("expected cursor on main_test.go, got %q", node.Text)
+	}
+	if m.message != "Match 2 of 2" {
+		t.Errorf("expected 'Match 2 of 2', got %q", m.message)
+	}
+
+	// Wraps around to the first match
+	m = press(m, runes("n")...)
+	if node := m.currentNode(); node.Text != "main.go" {
+		t.Errorf("expected cursor to wrap to main.go, got %q", node.Text)
+	}
+
+	m = press(m, runes("N")...)
+	if node := m.currentNode(); node.Text != "main_test.go" {
+		t.Errorf("expected cursor to wrap back to main_test.go, got %q", node.Text)
+	}
+}
+
+func TestSearchCancelRestoresCursor(t *testing.T) {
+	m := foldedModel(searchTree)
+	m = press(m, runes("/READ")...)
+	if node := m.currentNode(); node.Text != "README.md" {
+		t.Fatalf("expected cursor on README.md, got %q", node.Text)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.searching || m.matcher != nil {
+		t.Error("expected Esc to cancel the search")
+	}
+	if node := m.currentNode(); node.Text != "src" {
+		t.Errorf("expected cursor back on src, got %q", node.Text)
+	}
+}
+
+func TestSearchNotFound(t *testing.T) {
+	m := foldedModel(searchTree)
+	m = press(m, runes("/nothing")...)
+	if !strings.HasPrefix(m.message, "Pattern not found") {
+		t.Errorf("expected not found message, got %q", m.message)
+	}
+}
+
+func TestSearchOptions(t *testing.T) {
+	m := foldedModel(searchTree)
+	m = press(m, runes("/readme")...)
+	if m.currentNode().Text == "README.md" {
+		t.Error("expected search to be case-sensitive by default")
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}, Alt: true})
+	if node := m.currentNode(); node.Text != "README.md" {
+		t.Errorf("expected case-insensitive match on README.md, got %q", node.Text)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	m = press(m, runes("/^main_.*go$")...)
+	if m.currentNode().Text == "main_test.go" {
+		t.Error("expected a plain search by default")
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}, Alt: true})
+	if node := m.currentNode(); node.Text != "main_test.go" {
+		t.Errorf("expected regex match on main_test.go, got %q", node.Text)
+	}
+}
+
+func TestSearchInvalidRegex(t *testing.T) {
+	m := foldedModel(searchTree)
+	m.searchOpts.Regex = true
+	m = press(m, runes("/(")...)
+	if !strings.HasPrefix(m.message, "Invalid pattern") {
+		t.Errorf("expected invalid pattern message, got %q", m.message)
+	}
+	if m.matcher != nil {
+		t.Error("expected no matcher for an invalid pattern")
+	}
+}
+
+func TestSearchNextWithoutSearch(t *testing.T) {
+	m := foldedModel(searchTree)
+	m = press(m, runes("n")...)
+	if m.message != "No previous search" {
+		t.Errorf("expected 'No previous search', got %q", m.message)
+	}
+}
+
+func TestSearchFromEditMode(t *testing.T) {
+	m := foldedModel(searchTree)
+	m.setMode(ModeEdit)
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlF})
+	m = press(m, runes("README")...)
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != ModeEdit || !m.textInput.Focused() {
+		t.Error("expected to return to edit mode")
+	}
+	if m.textInput.Value() != "README.md" {
+		t.Errorf("expected text input on README.md, got %q", m.textInput.Value())
+	}
+}
@@ -1,13 +1,29 @@
 package ui
 
 import (
-	"github.com/atotto/clipboard"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/radish-miyazaki/ttree/internal/tree"
+	"github.com/radish-miyazaki/ttree/internal/render"
 )
 
 // Update implements tea.Model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.MouseMsg); ok {
+		return m.handleMouse(msg)
+	}
+
+	newModel, cmd := m.update(msg)
+	m = newModel.(Model)
+	m.scrollEditor()
+	m.scrollPreview()
+	return m, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -19,106 +35,362 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	// Handle text input updates
-	if m.mode == ModeEdit {
+	if m.searching {
 		var cmd tea.Cmd
-		m.textInput, cmd = m.textInput.Update(msg)
-		// Sync text to node in real-time
-		if node := m.currentNode(); node != nil {
-			node.Text = m.textInput.Value()
-		}
+		m.searchInput, cmd = m.searchInput.Update(msg)
+		return m, cmd
+	}
+	if m.filtering {
+		var cmd tea.Cmd
+		m.filterInput, cmd = m.filterInput.Update(msg)
+		return m, cmd
+	}
+	if m.commenting {
+		var cmd tea.Cmd
+		m.commentInput, cmd = m.commentInput.Update(msg)
 		return m, cmd
 	}
+	if m.mode == ModeEdit {
+		return m, m.updateTextInput(msg)
+	}
 
 	return m, nil
 }
 
+// updateTextInput forwards msg to the text input and syncs the node in real-time
+func (m *Model) updateTextInput(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	m.saveCurrentEdit()
+	return cmd
+}
+
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	m.copied = false
 	m.message = ""
 
+	if m.quitting {
+		return m.handleQuitConfirm(msg)
+	}
+	if m.confirmingPrint {
+		return m.handlePrintConfirm(msg)
+	}
+	if m.choosingFormat {
+		return m.handleFormatChoice(msg)
+	}
+	if m.searching {
+		return m.handleSearchKey(msg)
+	}
+	if m.filtering {
+		return m.handleFilterKey(msg)
+	}
+	if m.commenting {
+		return m.handleCommentKey(msg)
+	}
+	if m.showingHelp {
+		return m.handleHelpKey(msg)
+	}
+
+	// Pasting several lines adds them as nodes rather than text. Many
+	// terminals send the line breaks of a paste as \r.
+	if msg.Paste && strings.ContainsAny(strings.TrimRight(string(msg.Runes), "\r\n"), "\r\n") {
+		m.pasteText(string(msg.Runes))
+		return m, nil
+	}
+
+	// In normal mode, typed characters may form a sequence such as "dd"
+	if m.mode == ModeNormal && msg.Type == tea.KeyRunes && !msg.Alt && !msg.Paste {
+		seq := m.pending + string(msg.Runes)
+		m.pending = ""
+		if m.keys.Normal.isPrefix(seq) {
+			m.pending = seq
+			return m, nil
+		}
+		msg.Runes = []rune(seq)
+	} else {
+		m.pending = ""
+	}
+
+	keys := m.keys.forMode(m.mode)
+
+	if matches(msg, keys.Help) {
+		m.startHelp()
+		return m, nil
+	}
+
 	// Handle quit
-	if matches(msg, m.keys.Quit) {
+	if matches(msg, keys.Quit) {
 		m.saveCurrentEdit()
-		return m, tea.Quit
+		if m.printOnExit {
+			m.confirmingPrint = true
+			m.message = "Print the tree to stdout? (y/n, other keys cancel)"
+			return m, nil
+		}
+		return m.quit()
 	}
 
-	// Handle copy
-	if matches(msg, m.keys.Copy) {
-		m.saveCurrentEdit()
-		output := m.renderer.Render(m.tree)
-		if err := clipboard.WriteAll(output); err == nil {
-			m.copied = true
-			m.message = "Copied to clipboard!"
+	// Handle save
+	if matches(msg, keys.Save) {
+		if err := m.save(); err != nil {
+			m.message = "Failed to save: " + err.Error()
 		} else {
-			m.message = "Failed to copy: " + err.Error()
+			m.message = "Saved " + m.path
 		}
 		return m, nil
 	}
 
+	// Handle copy
+	if matches(msg, keys.Copy) {
+		m.copyAs("ascii")
+		return m, nil
+	}
+	if matches(msg, keys.PageUp) {
+		m.pageCursor(-m.paneHeight())
+		return m, nil
+	}
+	if matches(msg, keys.PageDown) {
+		m.pageCursor(m.paneHeight())
+		return m, nil
+	}
+	if matches(msg, keys.EditComment) {
+		return m, m.startComment()
+	}
+	if matches(msg, keys.Search) {
+		return m, m.startSearch()
+	}
+	if matches(msg, keys.SearchNext) {
+		m.searchNext(true)
+		return m, nil
+	}
+	if matches(msg, keys.SearchPrev) {
+		m.searchNext(false)
+		return m, nil
+	}
+	if matches(msg, keys.Filter) {
+		return m, m.startFilter()
+	}
+	if matches(msg, keys.FilterOutput) {
+		m.toggleFilterOutput()
+		return m, nil
+	}
+	if matches(msg, keys.CopyAs) {
+		m.choosingFormat = true
+		m.message = "Copy as: " + formatMenu()
+		return m, nil
+	}
+
+	// Undo / Redo
+	if matches(msg, keys.Undo) {
+		m.undo()
+		return m, nil
+	}
+	if matches(msg, keys.Redo) {
+		m.redo()
+		return m, nil
+	}
+
+	// Switch modes
+	if matches(msg, keys.ExitEdit) {
+		m.setMode(ModeNormal)
+		return m, nil
+	}
+	if matches(msg, keys.Insert) {
+		m.setMode(ModeEdit)
+		m.textInput.CursorStart()
+		return m, textinput.Blink
+	}
+	if matches(msg, keys.Append) {
+		m.setMode(ModeEdit)
+		m.textInput.CursorEnd()
+		return m, textinput.Blink
+	}
+
 	// Navigation
-	if matches(msg, m.keys.Up) {
+	if matches(msg, keys.Up) {
 		m.moveCursor(-1)
 		return m, nil
 	}
-	if matches(msg, m.keys.Down) {
+	if matches(msg, keys.Down) {
 		m.moveCursor(1)
 		return m, nil
 	}
+	if matches(msg, keys.Top) {
+		m.moveCursor(-m.cursor)
+		return m, nil
+	}
+	if matches(msg, keys.Bottom) {
+		m.moveCursor(len(m.nodes) - 1 - m.cursor)
+		return m, nil
+	}
 
-	// Indent / Unindent
-	if matches(msg, m.keys.Indent) {
-		m.saveCurrentEdit()
-		if node := m.currentNode(); node != nil {
-			m.tree.Indent(node)
-			m.refreshNodes()
-			m.focusNode(node)
-		}
+	// Fold / Unfold. In edit mode the arrows only fold at the ends of the text.
+	if matches(msg, keys.Collapse) && (m.mode == ModeNormal || m.textInput.Position() == 0) {
+		m.collapseCurrent()
 		return m, nil
 	}
-	if matches(msg, m.keys.Unindent) {
-		m.saveCurrentEdit()
-		if node := m.currentNode(); node != nil {
-			m.tree.Unindent(node)
-			m.refreshNodes()
-			m.focusNode(node)
+	if matches(msg, keys.Expand) && (m.mode == ModeNormal || m.textInput.Position() == len([]rune(m.textInput.Value()))) {
+		m.expandCurrent()
+		return m, nil
+	}
+	if matches(msg, keys.CollapseAll) {
+		m.foldToDepth(1)
+		m.message = "Collapsed all"
+		return m, nil
+	}
+	if matches(msg, keys.ExpandAll) {
+		m.foldToDepth(0)
+		m.message = "Expanded all"
+		return m, nil
+	}
+	if i := matchIndex(msg, keys.FoldLevel); i >= 0 {
+		m.foldToDepth(i + 1)
+		m.message = fmt.Sprintf("Showing %d level(s)", i+1)
+		return m, nil
+	}
+	if matches(msg, keys.ToggleFolded) {
+		m.renderer.IncludeCollapsed = !m.renderer.IncludeCollapsed
+		if m.renderer.IncludeCollapsed {
+			m.message = "Preview and copy include folded content"
+		} else {
+			m.message = "Preview and copy hide folded content"
 		}
 		return m, nil
 	}
 
-	// Enter - create new sibling
-	if matches(msg, m.keys.Enter) {
-		m.saveCurrentEdit()
-		if node := m.currentNode(); node != nil {
-			newNode := tree.NewNode("")
-			m.tree.InsertAfter(node, newNode)
-			m.refreshNodes()
-			m.focusNode(newNode)
-		}
+	if matches(msg, keys.CycleStyle) {
+		m.cycleStyle()
+		return m, nil
+	}
+
+	// Indent / Unindent
+	if matches(msg, keys.Indent) {
+		m.moveNode(m.tree.Indent, "indent")
+		return m, nil
+	}
+	if matches(msg, keys.Unindent) {
+		m.moveNode(m.tree.Unindent, "unindent")
 		return m, nil
 	}
 
+	// Move among siblings
+	if matches(msg, keys.MoveUp) {
+		m.moveNode(m.tree.MoveUp, "move up")
+		return m, nil
+	}
+	if matches(msg, keys.MoveDown) {
+		m.moveNode(m.tree.MoveDown, "move down")
+		return m, nil
+	}
+
+	// Enter - create new sibling and edit it
+	if matches(msg, keys.Enter) {
+		m.openNode(m.tree.InsertAfter)
+		return m, textinput.Blink
+	}
+	if matches(msg, keys.OpenAbove) {
+		m.openNode(m.tree.InsertBefore)
+		return m, textinput.Blink
+	}
+
 	// Delete
-	if matches(msg, m.keys.Delete) {
+	if matches(msg, keys.Delete) {
 		m.saveCurrentEdit()
 		if node := m.currentNode(); node != nil {
-			nextFocus := m.tree.Delete(node)
-			m.refreshNodes()
-			if nextFocus != nil {
-				m.focusNode(nextFocus)
-			}
+			m.record(m.snapshot(), "delete")
+			m.deleteNode(node)
 		}
 		return m, nil
 	}
 
+	// Register
+	if matches(msg, keys.Yank) {
+		m.yank()
+		return m, nil
+	}
+	if matches(msg, keys.Cut) {
+		m.cut()
+		return m, nil
+	}
+	if matches(msg, keys.Paste) {
+		m.paste(m.tree.InsertAfter)
+		return m, nil
+	}
+	if matches(msg, keys.PasteAbove) {
+		m.paste(m.tree.InsertBefore)
+		return m, nil
+	}
+	if matches(msg, keys.PasteChild) {
+		m.paste(m.tree.InsertChild)
+		return m, nil
+	}
+
 	// Pass to text input
 	if m.mode == ModeEdit {
-		var cmd tea.Cmd
-		m.textInput, cmd = m.textInput.Update(msg)
-		if node := m.currentNode(); node != nil {
-			node.Text = m.textInput.Value()
+		return m, m.updateTextInput(msg)
+	}
+
+	return m, nil
+}
+
+// handlePrintConfirm answers the print-on-quit prompt. Like a saved file,
+// the printed tree includes folded nodes.
+func (m Model) handlePrintConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.confirmingPrint = false
+	switch msg.String() {
+	case "y", "Y":
+		r := *m.renderer
+		r.IncludeCollapsed = true
+		m.output = r.Render(m.exportTree())
+		return m.quit()
+	case "n", "N":
+		m.output = ""
+		return m.quit()
+	}
+	return m, nil
+}
+
+// quit exits, asking to save first if a file has unsaved changes. A tree
+// without a file has nowhere to be saved, so it quits straight away.
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	if m.dirty && m.path != "" {
+		m.quitting = true
+		m.message = "Unsaved changes. Save before quitting? (y/n, other keys cancel)"
+		return m, nil
+	}
+	return m, tea.Quit
+}
+
+// handleQuitConfirm answers the save-on-quit prompt
+func (m Model) handleQuitConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.quitting = false
+	switch msg.String() {
+	case "y", "Y":
+		if err := m.save(); err != nil {
+			m.message = "Failed to save: " + err.Error()
+			return m, nil
 		}
-		return m, cmd
+		return m, tea.Quit
+	case "n", "N":
+		return m, tea.Quit
 	}
+	return m, nil
+}
 
+// handleFormatChoice answers the copy-as prompt with a format number
+func (m Model) handleFormatChoice(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.choosingFormat = false
+	formats := render.FormatNames()
+	if i, err := strconv.Atoi(msg.String()); err == nil && i >= 1 && i <= len(formats) {
+		m.copyAs(formats[i-1])
+	}
 	return m, nil
 }
+
+// formatMenu lists the export formats with the numbers that choose them
+func formatMenu() string {
+	var items []string
+	for i, name := range render.FormatNames() {
+		items = append(items, fmt.Sprintf("%d:%s", i+1, name))
+	}
+	return strings.Join(items, " ")
+}
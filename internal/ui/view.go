@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/radish-miyazaki/ttree/internal/render"
 )
 
 var (
@@ -38,9 +40,45 @@ var (
 	helpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("240"))
 
+	// Help overlay styles
+	helpTitleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("62"))
+	helpKeyStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("120"))
+
+	// Hidden child count of folded nodes
+	foldStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240"))
+
+	// Search match style
+	matchStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("220")).
+			Foreground(lipgloss.Color("235"))
+
+	// Node comments
+	commentStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("244")).
+			Italic(true)
+
+	// Drag and drop indicator
+	dropStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("212"))
+
 	// Status message style
 	statusStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("120"))
+
+	// Mode indicator styles
+	normalModeStyle = lipgloss.NewStyle().
+			Bold(true).
+			Background(lipgloss.Color("62")).
+			Foreground(lipgloss.Color("230"))
+	editModeStyle = lipgloss.NewStyle().
+			Bold(true).
+			Background(lipgloss.Color("120")).
+			Foreground(lipgloss.Color("235"))
 )
 
 // View implements tea.Model
@@ -48,19 +86,22 @@ func (m Model) View() string {
 	if m.width == 0 {
 		return "Loading..."
 	}
+	if m.showingHelp {
+		return m.buildHelpView()
+	}
 
 	// Calculate pane widths
 	totalWidth := m.width
-	editorWidth := totalWidth/2 - 2
+	editorWidth := m.editorPaneWidth() - 2
 	previewWidth := totalWidth - editorWidth - 4
 
 	// Build editor view
-	editorContent := m.buildEditorView(editorWidth)
-	editorPane := editorStyle.Width(editorWidth).Height(m.height - 5).Render(editorContent)
+	editorContent := truncate(m.buildEditorView(editorWidth), editorWidth-2)
+	editorPane := editorStyle.Width(editorWidth).Height(m.paneHeight()).Render(editorContent)
 
 	// Build preview view
-	previewContent := m.buildPreviewView()
-	previewPane := previewStyle.Width(previewWidth).Height(m.height - 5).Render(previewContent)
+	previewContent := truncate(window(m.buildPreviewView(), m.previewOffset, m.paneHeight()), previewWidth-2)
+	previewPane := previewStyle.Width(previewWidth).Height(m.paneHeight()).Render(previewContent)
 
 	// Combine panes
 	content := lipgloss.JoinHorizontal(lipgloss.Top, editorPane, previewPane)
@@ -69,33 +110,73 @@ func (m Model) View() string {
 	help := m.buildHelpLine()
 
 	// Build status line
-	status := ""
-	if m.message != "" {
-		status = statusStyle.Render(m.message)
+	status := m.buildModeIndicator()
+	if m.commenting {
+		help = helpStyle.Render(" Comment for " + m.currentNode().Text + " │ Enter:save │ Esc:cancel ")
+		status = m.commentInput.View()
+	} else if m.searching {
+		status += " " + m.searchInput.View() + m.buildSearchFlags()
+	} else if m.filtering {
+		status += " " + m.filterInput.View() + m.buildSearchFlags()
+	} else if m.message != "" {
+		status += " " + statusStyle.Render(m.message)
 	}
 
 	return lipgloss.JoinVertical(lipgloss.Left,
-		titleStyle.Render(" ttree - Tree Editor"),
+		titleStyle.Render(m.buildTitle()),
 		content,
 		help,
 		status,
 	)
 }
 
+func (m Model) buildTitle() string {
+	title := " ttree - Tree Editor"
+	if m.path != "" {
+		title = " ttree - " + m.path
+	}
+	if m.filter != nil {
+		title += " [filter: " + m.filterInput.Value() + "]"
+	}
+	if m.dirty {
+		title += " [+]"
+	}
+	return title
+}
+
 func (m Model) buildEditorView(width int) string {
 	var lines []string
 
-	for i, node := range m.nodes {
+	end := min(m.editorOffset+m.paneHeight(), len(m.nodes))
+	for i := m.editorOffset; i < end; i++ {
+		node := m.nodes[i]
+
 		// Build indentation
 		depth := node.Depth()
 		indent := strings.Repeat("  ", depth-1)
 
+		// Folded nodes show a marker and the number of hidden nodes. The
+		// filter shows matches inside folded nodes, so it shows no markers.
+		bullet := "• "
+		suffix := ""
+		if m.filter == nil && !node.Expanded && len(node.Children) > 0 {
+			bullet = "▸ "
+			suffix = fmt.Sprintf(" [+%d]", node.DescendantCount())
+		}
+
 		// Build line content
 		var line string
-		if i == m.cursor {
+		if i == m.cursor && m.mode == ModeNormal {
+			// Current line, highlighted
+			text := node.Text
+			if text == "" {
+				text = " "
+			}
+			line = indent + bullet + m.highlight(text, selectedStyle)
+		} else if i == m.cursor {
 			// Current line with text input
-			prefix := indent + "• "
-			inputWidth := width - len(prefix) - 2
+			prefix := indent + bullet
+			inputWidth := width - lipgloss.Width(prefix) - lipgloss.Width(suffix) - 2
 			if inputWidth < 10 {
 				inputWidth = 10
 			}
@@ -107,7 +188,20 @@ func (m Model) buildEditorView(width int) string {
 			if text == "" {
 				text = " "
 			}
-			line = indent + "• " + text
+			line = indent + bullet + m.highlight(text, normalStyle)
+		}
+		if suffix != "" {
+			line += foldStyle.Render(suffix)
+		}
+		if node.Comment != "" && !(i == m.cursor && m.mode == ModeEdit) {
+			line += commentStyle.Render("  " + render.CommentMarker + node.Comment)
+		}
+		if m.dropTarget != nil && m.dropTarget.node == node {
+			if m.dropTarget.into {
+				line += dropStyle.Render(" ← move into")
+			} else {
+				line += dropStyle.Render(" ← move below")
+			}
 		}
 
 		lines = append(lines, line)
@@ -116,19 +210,76 @@ func (m Model) buildEditorView(width int) string {
 	return strings.Join(lines, "\n")
 }
 
+// truncate cuts each line of s to at most width display cells, so that
+// long lines don't wrap inside a pane
+func truncate(s string, width int) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, max(width, 1), "…")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlight renders text in base with search matches picked out
+func (m Model) highlight(text string, base lipgloss.Style) string {
+	if m.matcher == nil {
+		return base.Render(text)
+	}
+	var sb strings.Builder
+	pos := 0
+	for _, r := range m.matcher.Ranges(text) {
+		sb.WriteString(base.Render(text[pos:r[0]]))
+		sb.WriteString(matchStyle.Render(text[r[0]:r[1]]))
+		pos = r[1]
+	}
+	if pos < len(text) {
+		sb.WriteString(base.Render(text[pos:]))
+	}
+	return sb.String()
+}
+
+func (m Model) buildSearchFlags() string {
+	var prompt string
+	if m.searchOpts.Regex {
+		prompt += helpStyle.Render(" [regex]")
+	}
+	if m.searchOpts.IgnoreCase {
+		prompt += helpStyle.Render(" [ignore case]")
+	}
+	return prompt
+}
+
 func (m Model) buildPreviewView() string {
-	return m.renderer.Render(m.tree)
+	var sb strings.Builder
+	lines := m.renderer.Lines(m.exportTree())
+	current := m.previewLine(lines)
+	for i, line := range lines {
+		if i == current {
+			sb.WriteString(line.Prefix + selectedStyle.Render(line.Text) + line.Suffix() + "\n")
+		} else {
+			sb.WriteString(line.String() + "\n")
+		}
+	}
+	return sb.String()
 }
 
-func (m Model) buildHelpLine() string {
-	keys := []string{
-		"↑↓:move",
-		"Tab:indent",
-		"S-Tab:unindent",
-		"Enter:new",
-		"C-d:delete",
-		"C-c:copy",
-		"C-q:quit",
-	}
-	return helpStyle.Render(fmt.Sprintf(" %s ", strings.Join(keys, " │ ")))
+// previewLine returns the index of the preview line showing the current
+// node. If the node is hidden in the preview, the line of its nearest
+// shown ancestor is returned, or -1 if there is none.
+func (m Model) previewLine(lines []render.Line) int {
+	for n := m.currentNode(); n != nil; n = n.Parent {
+		for i, line := range lines {
+			if line.NodeID == n.ID {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (m Model) buildModeIndicator() string {
+	if m.mode == ModeEdit {
+		return editModeStyle.Render(" EDIT ")
+	}
+	return normalModeStyle.Render(" NORMAL ")
 }
@@ -1,22 +1,212 @@
 package main
 
 import (
+	"cmp"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/radish-miyazaki/ttree/internal/clipboard"
+	"github.com/radish-miyazaki/ttree/internal/config"
+	"github.com/radish-miyazaki/ttree/internal/dirtree"
+	"github.com/radish-miyazaki/ttree/internal/document"
+	"github.com/radish-miyazaki/ttree/internal/render"
 	"github.com/radish-miyazaki/ttree/internal/ui"
 )
 
 func main() {
-	p := tea.NewProgram(
-		ui.New(),
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		err := runRender(os.Args[2:])
+		if err == flag.ErrHelp {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	importOpts := dirtree.DefaultOptions()
+	fromDir := flag.String("from-dir", "", "import the contents of `dir` as the tree")
+	flag.IntVar(&importOpts.MaxDepth, "depth", 0, "maximum directory depth to import (0 = unlimited)")
+	flag.BoolVar(&importOpts.Hidden, "hidden", false, "include hidden files when importing")
+	noGitIgnore := flag.Bool("no-gitignore", false, "include files matched by .gitignore when importing")
+	configPath := flag.String("config", "", "read preferences and key bindings from `file` (default: $XDG_CONFIG_HOME/ttree/config.toml)")
+	styleName := flag.String("style", "", "tree `style` for the preview and copy: "+strings.Join(render.StyleNames(), ", ")+" (default: the file's style)")
+	commentAlign := flag.String("comment-align", "", "comment `alignment` for the preview and copy: "+strings.Join(render.CommentAlignNames(), ", ")+" (default: column)")
+	commentDelimiter := flag.String("comment-delimiter", "", "also align text after `delim` (such as -- or #) as a comment")
+	printTree := flag.Bool("print", false, "offer to print the tree to stdout when quitting, drawing the editor on the terminal")
+	split := flag.Int("split", 0, "`percent` (20 to 80) of the width given to the editor pane (default: 50)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file | -]\n       %s render [flags] [file]\n\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	importOpts.GitIgnore = !*noGitIgnore
+
+	cfg, err := loadConfig(*configPath)
+	if err == nil && *split != 0 {
+		if err = ui.CheckSplit(*split); err != nil {
+			err = fmt.Errorf("--split: %w", err)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	// Flags take precedence over the config file
+	*styleName = cmp.Or(*styleName, cfg.Style)
+	*commentAlign = cmp.Or(*commentAlign, cfg.CommentAlign, "column")
+	*commentDelimiter = cmp.Or(*commentDelimiter, cfg.CommentDelimiter)
+	*split = cmp.Or(*split, cfg.Split)
+
+	var opts []ui.Option
+	if *fromDir != "" {
+		opts, err = importDir(*fromDir, flag.Arg(0), importOpts)
+	} else {
+		opts, err = loadTree(flag.Arg(0))
+	}
+	if err == nil && *styleName != "" {
+		var style render.Style
+		style, err = render.LookupStyle(*styleName)
+		opts = append(opts, ui.WithStyle(style))
+	}
+	if err == nil {
+		var align render.CommentAlign
+		align, err = render.ParseCommentAlign(*commentAlign)
+		opts = append(opts, ui.WithCommentAlign(align), ui.WithCommentDelimiter(*commentDelimiter))
+	}
+	if err == nil && *split != 0 {
+		opts = append(opts, ui.WithSplit(*split))
+	}
+	// The terminal, for drawing the editor with --print and for OSC 52
+	var tty io.Writer
+	if f, ttyErr := os.OpenFile("/dev/tty", os.O_WRONLY, 0); ttyErr == nil {
+		defer f.Close()
+		tty = f
+	}
+	if err == nil {
+		var cb clipboard.Backend
+		if cb, err = clipboard.New(cfg.Clipboard, tty, cfg.ClipboardFile); err != nil && cfg.File != "" {
+			err = fmt.Errorf("%s: clipboard: %w", cfg.File, err)
+		}
+		opts = append(opts, ui.WithClipboard(cb))
+	}
+	if err == nil {
+		keys := ui.DefaultKeyMap()
+		if err = keys.Apply(cfg.Keys.Normal, cfg.Keys.Edit); err != nil {
+			err = fmt.Errorf("%s: keys: %w", cfg.File, err)
+		}
+		opts = append(opts, ui.WithKeyMap(keys))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	programOpts := []tea.ProgramOption{
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
-	)
+	}
+	if !isTerminal(os.Stdin) {
+		// The tree was read from stdin, so take key input from the terminal
+		programOpts = append(programOpts, tea.WithInputTTY())
+	}
+	if *printTree {
+		// Stdout is kept for the tree, so draw the editor on the terminal
+		if tty == nil {
+			fmt.Fprintln(os.Stderr, "Error: --print needs a terminal")
+			os.Exit(1)
+		}
+		lipgloss.DefaultRenderer().SetOutput(termenv.NewOutput(tty))
+		programOpts = append(programOpts, tea.WithOutput(tty))
+		opts = append(opts, ui.WithPrint())
+	}
+
+	p := tea.NewProgram(ui.New(opts...), programOpts...)
 
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error: %v\n", err)
+	model, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	if *printTree {
+		out := model.(ui.Model).Output()
+		if out == "" {
+			// Quit without printing, so that scripts can tell
+			os.Exit(1)
+		}
+		fmt.Print(out)
+	}
+}
+
+// loadConfig reads the config file at path, or at the default location if
+// path is empty
+func loadConfig(path string) (config.Config, error) {
+	if path == "" {
+		var err error
+		if path, err = config.Path(); err != nil {
+			// No home directory to look in, so use the defaults
+			return config.Config{}, nil
+		}
+	} else if _, err := os.Stat(path); err != nil {
+		// A config file given explicitly must exist
+		return config.Config{}, err
+	}
+	return config.Load(path)
+}
+
+// loadTree returns the options that open path in the editor.
+// "-" or piped stdin reads the tree from stdin; a missing file starts empty.
+func loadTree(path string) ([]ui.Option, error) {
+	if path == "-" || (path == "" && !isTerminal(os.Stdin)) {
+		t, format, err := document.Read(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return []ui.Option{ui.WithTree(t), ui.WithFormat(format)}, nil
+	}
+	if path == "" {
+		return nil, nil
+	}
+
+	opts := []ui.Option{ui.WithPath(path)}
+	t, format, err := document.Load(path)
+	if os.IsNotExist(err) {
+		return opts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(opts, ui.WithTree(t), ui.WithFormat(format)), nil
+}
+
+// importDir returns the options that open dir in the editor, saving to path
+// if it is given as tree(1) prints it: the directory above its entries
+func importDir(dir, path string, importOpts dirtree.Options) ([]ui.Option, error) {
+	t, err := dirtree.Build(dir, importOpts)
+	if err != nil {
+		return nil, err
+	}
+	format := document.Format{ASCII: true, Style: document.FormatASCII.Style, Header: true}
+	opts := []ui.Option{ui.WithTree(t), ui.WithFormat(format)}
+	if path != "" {
+		opts = append(opts, ui.WithPath(path))
+	}
+	return opts, nil
+}
+
+// isTerminal reports whether f is a character device
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/radish-miyazaki/ttree/internal/document"
+	"github.com/radish-miyazaki/ttree/internal/render"
+)
+
+// runRender implements "ttree render": it reads a tree from a file or stdin
+// and writes it to stdout without starting the editor
+func runRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	configPath := fs.String("config", "", "read preferences from `file` (default: $XDG_CONFIG_HOME/ttree/config.toml)")
+	styleName := fs.String("style", "", "tree `style` for ascii output: "+strings.Join(render.StyleNames(), ", ")+" (default: default)")
+	format := fs.String("format", "ascii", "output `format`: "+strings.Join(render.FormatNames(), ", "))
+	commentAlign := fs.String("comment-align", "", "comment `alignment` for ascii output: "+strings.Join(render.CommentAlignNames(), ", ")+" (default: column)")
+	commentDelimiter := fs.String("comment-delimiter", "", "also align text after `delim` (such as -- or #) as a comment")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s render [flags] [file]\n\nReads an outline or ASCII tree from file (or stdin) and prints it.\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	// Flags take precedence over the config file
+	style, err := render.LookupStyle(cmp.Or(*styleName, cfg.Style, "default"))
+	if err != nil {
+		return err
+	}
+	align, err := render.ParseCommentAlign(cmp.Or(*commentAlign, cfg.CommentAlign, "column"))
+	if err != nil {
+		return err
+	}
+	r := render.NewRenderer()
+	r.Style = style
+	r.IncludeCollapsed = true
+	r.CommentAlign = align
+	r.CommentDelimiter = cmp.Or(*commentDelimiter, cfg.CommentDelimiter)
+	exporter, err := render.LookupExporter(*format, r)
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if path := fs.Arg(0); path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	t, _, err := document.Read(in)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(os.Stdout, exporter.Export(t))
+	return err
+}